@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/config"
+	"go.uber.org/zap"
+)
+
+var (
+	logger    *zap.Logger
+	validator *auth.Validator
+)
+
+func main() {
+	cfg, err := config.LoadAuth()
+	if err != nil {
+		log.Fatalf("failed to load configuration. Error: %s", err.Error())
+	}
+
+	loggerConfig := zap.NewProductionConfig()
+	loggerConfig.Level = zap.NewAtomicLevelAt(cfg.ZapLevel())
+	loggerProduction, err := loggerConfig.Build()
+	if err != nil {
+		log.Fatalf("failed to initiate logger. Error: %s", err.Error())
+	}
+	logger = loggerProduction
+
+	validator, err = auth.NewValidator(cfg.Auth.Validator())
+	if err != nil {
+		logger.Fatal("Failed to load token validation keys", zap.Error(err))
+	}
+
+	lambda.Start(handler)
+}
+
+func handler(req auth.AuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	resp, err := validator.Authorize(req)
+	if err != nil {
+		logger.Warn("Denied request", zap.String("method_arn", req.MethodArn))
+		return resp, err
+	}
+
+	logger.Info("Authorized request", zap.String("principal", resp.PrincipalID), zap.String("method_arn", req.MethodArn))
+	return resp, nil
+}
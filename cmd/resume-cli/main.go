@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/bkimbrough88/resume-backend/pkg/linkedin"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const usage = `Usage: resume-cli <command> [flags]
+
+Commands:
+  import-linkedin        Build a resume from a LinkedIn "download your data" archive
+  backfill-tenant-keys   Copy a table from before tenants into its tenant scoped replacement
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import-linkedin":
+		err = importLinkedin(os.Args[2:])
+	case "backfill-tenant-keys":
+		err = backfillTenantKeys(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func importLinkedin(args []string) error {
+	flags := flag.NewFlagSet("import-linkedin", flag.ExitOnError)
+	zipPath := flags.String("zip", "", "path to the LinkedIn export archive (required)")
+	userId := flags.String("user-id", "", "user_id to give the imported resume")
+	mergePath := flags.String("merge", "", "existing resume (YAML or JSON) to merge the import into")
+	format := flags.String("format", "yaml", "output format, yaml or json")
+	outPath := flags.String("out", "", "file to write the resume to, defaults to stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*zipPath) == 0 {
+		flags.Usage()
+		return fmt.Errorf("-zip is required")
+	}
+
+	archive, err := ioutil.ReadFile(*zipPath)
+	if err != nil {
+		return err
+	}
+
+	imported, err := linkedin.Parse(archive)
+	if err != nil {
+		return err
+	}
+
+	user := &models.User{}
+	if len(*mergePath) > 0 {
+		if user, err = readUser(*mergePath); err != nil {
+			return err
+		}
+	}
+	user = models.MergeUser(user, imported)
+
+	if len(*userId) > 0 {
+		user.UserId = *userId
+	}
+
+	var out []byte
+	switch strings.ToLower(*format) {
+	case "yaml":
+		out, err = models.MarshalUserYAML(user)
+	case "json":
+		if out, err = json.MarshalIndent(user, "", "  "); err == nil {
+			out = append(out, '\n')
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(*outPath) == 0 {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	return ioutil.WriteFile(*outPath, out, 0644)
+}
+
+// backfillTenantKeys copies users, organizations or memberships into the tables keyed on pk. Run it for each table
+// before pointing the API at the new ones, and again just before switching to pick up any writes made in between.
+func backfillTenantKeys(args []string) error {
+	flags := flag.NewFlagSet("backfill-tenant-keys", flag.ExitOnError)
+	from := flags.String("from", "", "table keyed on the bare id, e.g. resume_user (required)")
+	to := flags.String("to", "", "tenant scoped table to copy into, e.g. resume_tenant_user (required)")
+	idAttribute := flags.String("id", "", "hash key of the old table, user_id for users or org_id for organizations and memberships (required)")
+	region := flags.String("region", os.Getenv("AWS_REGION"), "AWS region of the tables")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*from) == 0 || len(*to) == 0 || len(*idAttribute) == 0 {
+		flags.Usage()
+		return fmt.Errorf("-from, -to and -id are required")
+	}
+
+	awsSession, err := session.NewSession(&aws.Config{Region: aws.String(*region)})
+	if err != nil {
+		return err
+	}
+
+	copied, err := models.BackfillTenantKeys(context.Background(), *from, *to, *idAttribute, dynamodb.New(awsSession), zap.NewNop())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("copied %d items from %s to %s\n", copied, *from, *to)
+	return nil
+}
+
+func readUser(path string) (*models.User, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		user := &models.User{}
+		if err := json.Unmarshal(data, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	doc, err := models.UnmarshalUserYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.User, nil
+}
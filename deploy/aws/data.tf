@@ -27,7 +27,18 @@ data "aws_iam_policy_document" "lambda_permissions" {
       "dynamodb:PutItem",
       "dynamodb:UpdateItem"
     ]
-    resources = [aws_dynamodb_table.table.arn]
+    resources = [
+      aws_dynamodb_table.table.arn,
+      aws_dynamodb_table.users.arn,
+      aws_dynamodb_table.shares.arn,
+      aws_dynamodb_table.api_keys.arn,
+      aws_dynamodb_table.orgs.arn,
+      aws_dynamodb_table.tenant_orgs.arn,
+      aws_dynamodb_table.memberships.arn,
+      aws_dynamodb_table.tenant_memberships.arn,
+      aws_dynamodb_table.resumes.arn,
+      aws_dynamodb_table.idempotency.arn
+    ]
   }
   statement {
     sid    = "LambdaLogs"
@@ -49,4 +60,10 @@ data "archive_file" "zip" {
   output_path = "${var.function_base_path}/resume-backend.zip"
   source_file = "${var.function_base_path}/resume-backend"
   type        = "zip"
+}
+
+data "archive_file" "authorizer_zip" {
+  output_path = "${var.authorizer_base_path}/authorizer.zip"
+  source_file = "${var.authorizer_base_path}/authorizer"
+  type        = "zip"
 }
\ No newline at end of file
@@ -13,6 +13,8 @@ resource "aws_iam_role_policy_attachment" "lambda_permissions" {
   role       = aws_iam_role.lambda_assumer.name
 }
 
+// The tables from before tenants were keyed on the bare id. Changing a table's key replaces it, so they are kept as they
+// were until `resume-cli backfill-tenant-keys` has copied them into the tenant scoped tables below.
 resource "aws_dynamodb_table" "table" {
   billing_mode = "PAY_PER_REQUEST"
   hash_key     = "user_id"
@@ -24,6 +26,134 @@ resource "aws_dynamodb_table" "table" {
   }
 }
 
+// Tenant scoped tables are keyed on pk, which is always "<tenant_id>#<id>"
+resource "aws_dynamodb_table" "users" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "pk"
+  name         = "resume_tenant_user"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "shares" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "share_id"
+  name         = "resume_share"
+
+  attribute {
+    name = "share_id"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+}
+
+resource "aws_dynamodb_table" "api_keys" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "key_id"
+  name         = "resume_api_key"
+
+  attribute {
+    name = "key_id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "orgs" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "org_id"
+  name         = "resume_org"
+
+  attribute {
+    name = "org_id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "tenant_orgs" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "pk"
+  name         = "resume_tenant_org"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "memberships" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "org_id"
+  name         = "resume_membership"
+  range_key    = "user_id"
+
+  attribute {
+    name = "org_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "user_id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "tenant_memberships" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "pk"
+  name         = "resume_tenant_membership"
+  range_key    = "user_id"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+
+  attribute {
+    name = "user_id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "resumes" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "pk"
+  name         = "resume_resume"
+  range_key    = "resume_id"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+
+  attribute {
+    name = "resume_id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "idempotency" {
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "pk"
+  name         = "resume_idempotency"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+}
+
 resource "aws_lambda_function" "resume_backend" {
   filename         = data.archive_file.zip.output_path
   function_name    = "ResumeBackend"
@@ -31,6 +161,43 @@ resource "aws_lambda_function" "resume_backend" {
   role             = aws_iam_role.lambda_assumer.arn
   runtime          = "go1.x"
   source_code_hash = data.archive_file.zip.output_base64sha256
+
+  environment {
+    variables = {
+      JWKS_URL                 = "https://${var.auth0_domain}/.well-known/jwks.json"
+      JWT_AUDIENCE             = var.auth0_audience
+      JWT_ISSUER               = "https://${var.auth0_domain}/"
+      SHARE_TOKEN_SECRET       = var.share_token_secret
+      // Routes behind the authorizer get the principal from it instead of validating the token again
+      TRUST_AUTHORIZER_CONTEXT = "true"
+      CORS_ALLOWED_ORIGINS     = join(",", var.cors_allowed_origins)
+      CORS_MAX_AGE             = "1h"
+      USERS_TABLE              = var.tenant_tables_backfilled ? aws_dynamodb_table.users.name : aws_dynamodb_table.table.name
+      RESUMES_TABLE            = aws_dynamodb_table.resumes.name
+      SHARES_TABLE             = aws_dynamodb_table.shares.name
+      API_KEYS_TABLE           = aws_dynamodb_table.api_keys.name
+      ORGANIZATIONS_TABLE      = var.tenant_tables_backfilled ? aws_dynamodb_table.tenant_orgs.name : aws_dynamodb_table.orgs.name
+      MEMBERSHIPS_TABLE        = var.tenant_tables_backfilled ? aws_dynamodb_table.tenant_memberships.name : aws_dynamodb_table.memberships.name
+      IDEMPOTENCY_TABLE        = aws_dynamodb_table.idempotency.name
+    }
+  }
+}
+
+resource "aws_lambda_function" "authorizer" {
+  filename         = data.archive_file.authorizer_zip.output_path
+  function_name    = "ResumeBackendAuthorizer"
+  handler          = "authorizer"
+  role             = aws_iam_role.lambda_assumer.arn
+  runtime          = "go1.x"
+  source_code_hash = data.archive_file.authorizer_zip.output_base64sha256
+
+  environment {
+    variables = {
+      JWKS_URL     = "https://${var.auth0_domain}/.well-known/jwks.json"
+      JWT_AUDIENCE = var.auth0_audience
+      JWT_ISSUER   = "https://${var.auth0_domain}/"
+    }
+  }
 }
 
 resource "aws_lambda_permission" "apigw" {
@@ -40,27 +207,27 @@ resource "aws_lambda_permission" "apigw" {
   source_arn    = "${aws_apigatewayv2_api.api.execution_arn}/*/*/*"
 }
 
+resource "aws_lambda_permission" "apigw_authorizer" {
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.authorizer.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_apigatewayv2_api.api.execution_arn}/authorizers/${aws_apigatewayv2_authorizer.auth.id}"
+}
+
 resource "aws_apigatewayv2_api" "api" {
   name          = "resume-api"
   protocol_type = "HTTP"
-
-  cors_configuration {
-    allow_headers = ["Authorization"]
-    allow_methods = ["GET", "POST", "DELETE", "OPTIONS"]
-    allow_origins = ["*"] // TODO: Make this restrict to https://brandon.thekimbroughs.net once we're done testing with it
-  }
+  // No cors_configuration, the function answers preflights itself so they match its routes and CORS policy
 }
 
 resource "aws_apigatewayv2_authorizer" "auth" {
-  api_id           = aws_apigatewayv2_api.api.id
-  authorizer_type  = "JWT"
-  identity_sources = ["$request.header.Authorization"]
-  name             = "auth0"
-
-  jwt_configuration {
-    audience = [var.auth0_audience]
-    issuer   = "https://${var.auth0_domain}/"
-  }
+  api_id                            = aws_apigatewayv2_api.api.id
+  authorizer_type                   = "REQUEST"
+  authorizer_uri                    = aws_lambda_function.authorizer.invoke_arn
+  authorizer_payload_format_version = "1.0"
+  authorizer_result_ttl_in_seconds  = 300
+  identity_sources                  = ["$request.header.Authorization"]
+  name                              = "resume-authorizer"
 }
 
 resource "aws_apigatewayv2_integration" "resume_backend" {
@@ -82,16 +249,203 @@ resource "aws_apigatewayv2_route" "get_user_by_key" {
 resource "aws_apigatewayv2_route" "put_user" {
   api_id             = aws_apigatewayv2_api.api.id
   authorizer_id      = aws_apigatewayv2_authorizer.auth.id
-  authorization_type = "JWT"
+  authorization_type = "CUSTOM"
   operation_name     = "Put User"
   route_key          = "POST /user"
   target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
 }
 
+resource "aws_apigatewayv2_route" "put_user_by_key" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Put User by Key"
+  route_key          = "POST /user/{id}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "import_linkedin" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Import LinkedIn Export"
+  route_key          = "POST /user/{id}/linkedin"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "tailor" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Tailor"
+  route_key          = "POST /user/{id}/tailor"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "get_ats_report" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Get ATS Report"
+  route_key          = "GET /user/{id}/ats-report"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "get_resumes" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorization_type = "NONE"
+  operation_name     = "Get Resumes"
+  route_key          = "GET /user/{id}/resumes"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "get_resume" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorization_type = "NONE"
+  operation_name     = "Get Resume"
+  route_key          = "GET /user/{id}/resumes/{resumeId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "put_resume" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Put Resume"
+  route_key          = "POST /user/{id}/resumes/{resumeId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "delete_resume" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Delete Resume"
+  route_key          = "DELETE /user/{id}/resumes/{resumeId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "create_share" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Create Share"
+  route_key          = "POST /user/{id}/shares"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "revoke_share" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Revoke Share"
+  route_key          = "DELETE /user/{id}/shares/{shareId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "get_shared" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorization_type = "NONE"
+  operation_name     = "Get Shared Resume"
+  route_key          = "GET /shared/{token}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "create_api_key" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Create API Key"
+  route_key          = "POST /user/{id}/api-keys"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "rotate_api_key" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Rotate API Key"
+  route_key          = "POST /user/{id}/api-keys/{keyId}/rotate"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "revoke_api_key" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Revoke API Key"
+  route_key          = "DELETE /user/{id}/api-keys/{keyId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "create_org" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Create Organization"
+  route_key          = "POST /orgs"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "get_org" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Get Organization"
+  route_key          = "GET /orgs/{orgId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "put_member" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Put Member"
+  route_key          = "POST /orgs/{orgId}/members/{userId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+resource "aws_apigatewayv2_route" "delete_member" {
+  api_id             = aws_apigatewayv2_api.api.id
+  authorizer_id      = aws_apigatewayv2_authorizer.auth.id
+  authorization_type = "CUSTOM"
+  operation_name     = "Delete Member"
+  route_key          = "DELETE /orgs/{orgId}/members/{userId}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
+// Preflights can't carry credentials, so they skip the authorizer
+resource "aws_apigatewayv2_route" "preflight" {
+  for_each = toset([
+    "/user",
+    "/user/{id}",
+    "/user/{id}/linkedin",
+    "/user/{id}/tailor",
+    "/user/{id}/ats-report",
+    "/user/{id}/resumes",
+    "/user/{id}/resumes/{resumeId}",
+    "/user/{id}/shares",
+    "/user/{id}/shares/{shareId}",
+    "/shared/{token}",
+    "/user/{id}/api-keys",
+    "/user/{id}/api-keys/{keyId}/rotate",
+    "/user/{id}/api-keys/{keyId}",
+    "/orgs",
+    "/orgs/{orgId}",
+    "/orgs/{orgId}/members/{userId}",
+  ])
+
+  api_id             = aws_apigatewayv2_api.api.id
+  authorization_type = "NONE"
+  operation_name     = "Preflight ${each.value}"
+  route_key          = "OPTIONS ${each.value}"
+  target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
+}
+
 resource "aws_apigatewayv2_route" "delete_user" {
   api_id             = aws_apigatewayv2_api.api.id
   authorizer_id      = aws_apigatewayv2_authorizer.auth.id
-  authorization_type = "JWT"
+  authorization_type = "CUSTOM"
   operation_name     = "Delete User"
   route_key          = "DELETE /user/{id}"
   target             = "integrations/${aws_apigatewayv2_integration.resume_backend.id}"
@@ -104,8 +458,28 @@ resource "aws_apigatewayv2_deployment" "deployment" {
     redeployment = sha1(join(",", tolist(
       [
         jsonencode(aws_apigatewayv2_integration.resume_backend),
+        jsonencode(aws_apigatewayv2_authorizer.auth),
         jsonencode(aws_apigatewayv2_route.get_user_by_key),
         jsonencode(aws_apigatewayv2_route.put_user),
+        jsonencode(aws_apigatewayv2_route.put_user_by_key),
+        jsonencode(aws_apigatewayv2_route.import_linkedin),
+        jsonencode(aws_apigatewayv2_route.tailor),
+        jsonencode(aws_apigatewayv2_route.get_ats_report),
+        jsonencode(aws_apigatewayv2_route.get_resumes),
+        jsonencode(aws_apigatewayv2_route.get_resume),
+        jsonencode(aws_apigatewayv2_route.put_resume),
+        jsonencode(aws_apigatewayv2_route.delete_resume),
+        jsonencode(aws_apigatewayv2_route.create_share),
+        jsonencode(aws_apigatewayv2_route.revoke_share),
+        jsonencode(aws_apigatewayv2_route.get_shared),
+        jsonencode(aws_apigatewayv2_route.create_api_key),
+        jsonencode(aws_apigatewayv2_route.rotate_api_key),
+        jsonencode(aws_apigatewayv2_route.revoke_api_key),
+        jsonencode(aws_apigatewayv2_route.create_org),
+        jsonencode(aws_apigatewayv2_route.get_org),
+        jsonencode(aws_apigatewayv2_route.put_member),
+        jsonencode(aws_apigatewayv2_route.delete_member),
+        jsonencode(aws_apigatewayv2_route.preflight),
         jsonencode(aws_apigatewayv2_route.delete_user)
       ]
     )))
@@ -162,7 +536,8 @@ resource "aws_cloudfront_cache_policy" "cors" {
           "Access-Control-Request-Headers",
           "Access-Control-Request-Method",
           "Authorization",
-          "Origin"
+          "Origin",
+          "X-Api-Key"
         ]
       }
     }
@@ -22,8 +22,32 @@ variable "base_domain_name" {
   default     = "thekimbroughs.net"
 }
 
+variable "share_token_secret" {
+  type        = string
+  description = "The secret used to sign share link tokens"
+  sensitive   = true
+}
+
+variable "tenant_tables_backfilled" {
+  type        = bool
+  description = "Point the function at the tenant scoped tables. Only set it once resume-cli backfill-tenant-keys has copied the users, organizations and memberships into them, and delete the old tables only after that."
+  default     = false
+}
+
+variable "cors_allowed_origins" {
+  type        = list(string)
+  description = "Origins browsers may call the API from, either exact or with a wildcard subdomain like https://*.example.com"
+  default     = ["https://brandon.thekimbroughs.net"]
+}
+
 variable "function_base_path" {
   type = string
   description = "The path to the function's binary"
   default = "../../build/bin"
+}
+
+variable "authorizer_base_path" {
+  type = string
+  description = "The path to the authorizer's binary"
+  default = "../../build/bin"
 }
\ No newline at end of file
@@ -6,4 +6,5 @@ require (
 	github.com/aws/aws-lambda-go v1.28.0
 	github.com/aws/aws-sdk-go v1.43.36
 	go.uber.org/zap v1.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -1,52 +1,173 @@
 package main
 
 import (
+	"context"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/config"
 	"github.com/bkimbrough88/resume-backend/pkg/handlers"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/storage"
 	"go.uber.org/zap"
 	"log"
-	"os"
+	"strings"
+	"time"
 )
 
 var (
 	svc    dynamodbiface.DynamoDBAPI
 	logger *zap.Logger
+	router *handlers.Router
 )
 
 func main() {
-	loggerProduction, err := zap.NewProduction()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load configuration. Error: %s", err.Error())
+	}
+
+	loggerConfig := zap.NewProductionConfig()
+	loggerConfig.Level = zap.NewAtomicLevelAt(cfg.ZapLevel())
+	loggerProduction, err := loggerConfig.Build()
 	if err != nil {
 		log.Fatalf("failed to initiate logger. Error: %s", err.Error())
 	}
 	logger = loggerProduction
 
-	region := os.Getenv("AWS_REGION")
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(region)},
-	)
+	applyConfig(cfg)
+
+	// Retries are left to the storage wrapper so they respect the request's deadline and the circuit breaker
+	awsConfig := &aws.Config{
+		Region:     aws.String(cfg.Region),
+		MaxRetries: aws.Int(0),
+	}
+	if len(cfg.Storage.Endpoint) > 0 {
+		awsConfig.Endpoint = aws.String(cfg.Storage.Endpoint)
+	}
+	awsSession, err := session.NewSession(awsConfig)
 	if err != nil {
 		logger.Error("Failed to establish new AWS session", zap.Error(err))
 		return
 	}
-	svc = dynamodb.New(awsSession)
+	svc = storage.New(dynamodb.New(awsSession), cfg.Storage.Resilience(), logger)
+
+	validator, err := auth.NewValidator(cfg.Auth.Validator())
+	if err != nil {
+		logger.Error("Failed to load token validation keys", zap.Error(err))
+		return
+	}
+
+	router = newRouter(cfg, validator)
 	lambda.Start(handler)
 }
 
-func handler(req events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
-	logger.Info("Received request", zap.Any("request", req))
-	switch req.HTTPMethod {
-	case "GET":
-		return handlers.GetUser(req, svc, logger)
-	case "POST":
-		return handlers.PutUser(req, svc, logger)
-	case "DELETE":
-		return handlers.DeleteUser(req, svc, logger)
-	default:
-		return handlers.UnhandledMethod(req, logger)
+// applyConfig sets the package level settings the config controls
+func applyConfig(cfg *config.Config) {
+	models.UsersTable = cfg.Tables.Users
+	models.ResumesTable = cfg.Tables.Resumes
+	models.SharesTable = cfg.Tables.Shares
+	models.ApiKeysTable = cfg.Tables.ApiKeys
+	models.OrganizationsTable = cfg.Tables.Organizations
+	models.MembershipsTable = cfg.Tables.Memberships
+	models.IdempotencyTable = cfg.Tables.Idempotency
+
+	handlers.MaxBodyBytes = cfg.Limits.MaxBodyBytes
+	handlers.MaxBodyDepth = cfg.Limits.MaxBodyDepth
+}
+
+func newRouter(cfg *config.Config, validator *auth.Validator) *handlers.Router {
+	if len(cfg.Cors.AllowedOrigins) == 0 {
+		logger.Warn("No CORS origins configured, browsers on other origins will not be able to call the API")
+	}
+
+	r := handlers.NewRouter()
+	r.Use(handlers.Cors(handlers.CorsPolicy{
+		AllowedOrigins:   cfg.Cors.AllowedOrigins,
+		AllowCredentials: cfg.Cors.AllowCredentials,
+		MaxAge:           cfg.Cors.MaxAge,
+	}))
+	r.Use(handlers.Authenticate(validator, cfg.Auth.TrustAuthorizer))
+	if cfg.Features.Idempotency {
+		r.Use(handlers.Idempotency(cfg.Features.IdempotencyTTL))
+	}
+	r.Handle("GET", "/user/{id}", handlers.GetUser)
+	r.Handle("POST", "/user", handlers.PutUser)
+	r.Handle("POST", "/user/{id}", handlers.PutUser)
+	r.Handle("DELETE", "/user/{id}", handlers.DeleteUser)
+	if cfg.Features.LinkedinImport {
+		r.Handle("POST", "/user/{id}/linkedin", handlers.ImportLinkedin)
+	}
+
+	if cfg.Features.Tailor {
+		r.Handle("POST", "/user/{id}/tailor", handlers.Tailor)
+	}
+	r.Handle("GET", "/user/{id}/ats-report", handlers.GetAtsReport)
+
+	r.Handle("GET", "/user/{id}/resumes", handlers.GetResumes)
+	r.Handle("GET", "/user/{id}/resumes/{resumeId}", handlers.GetResume)
+	r.Handle("POST", "/user/{id}/resumes/{resumeId}", handlers.PutResume)
+	r.Handle("DELETE", "/user/{id}/resumes/{resumeId}", handlers.DeleteResume)
+
+	if cfg.Features.Shares {
+		shares := handlers.NewShareHandler([]byte(cfg.Auth.ShareTokenSecret))
+		r.Handle("POST", "/user/{id}/shares", shares.CreateShare)
+		r.Handle("DELETE", "/user/{id}/shares/{shareId}", shares.RevokeShare)
+		r.Handle("GET", "/shared/{token}", shares.GetShared)
+	}
+
+	if cfg.Features.ApiKeys {
+		r.Handle("POST", "/user/{id}/api-keys", handlers.CreateApiKey)
+		r.Handle("POST", "/user/{id}/api-keys/{keyId}/rotate", handlers.RotateApiKey)
+		r.Handle("DELETE", "/user/{id}/api-keys/{keyId}", handlers.RevokeApiKey)
+	}
+
+	r.Handle("POST", "/orgs", handlers.CreateOrg)
+	r.Handle("GET", "/orgs/{orgId}", handlers.GetOrg)
+	r.Handle("POST", "/orgs/{orgId}/members/{userId}", handlers.PutMember)
+	r.Handle("DELETE", "/orgs/{orgId}/members/{userId}", handlers.DeleteMember)
+	return r
+}
+
+// deadlineMargin is how long before the function's deadline storage calls are cancelled, leaving time to respond with
+// a 504 rather than being killed mid-request
+const deadlineMargin = 500 * time.Millisecond
+
+func handler(ctx context.Context, req events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-deadlineMargin))
+		defer cancel()
 	}
+
+	req, reqLogger := handlers.WithRequestId(req, logger)
+	reqLogger.Info("Received request", zap.Any("request", redactCredentials(req)))
+	return router.Route(ctx, req, svc, reqLogger)
+}
+
+// redactCredentials returns a copy of the request that is safe to log
+func redactCredentials(req events.APIGatewayProxyRequest) events.APIGatewayProxyRequest {
+	headers := make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, handlers.ApiKeyHeader) {
+			value = "REDACTED"
+		}
+		headers[name] = value
+	}
+
+	multiValueHeaders := make(map[string][]string, len(req.MultiValueHeaders))
+	for name, values := range req.MultiValueHeaders {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, handlers.ApiKeyHeader) {
+			values = []string{"REDACTED"}
+		}
+		multiValueHeaders[name] = values
+	}
+
+	req.Headers = headers
+	req.MultiValueHeaders = multiValueHeaders
+	return req
 }
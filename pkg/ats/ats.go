@@ -0,0 +1,252 @@
+package ats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+const (
+	CheckMissingDates       = "missing_dates"
+	CheckOverlappingDates   = "overlapping_dates"
+	CheckNoActionVerb       = "no_action_verb"
+	CheckLongBullet         = "long_bullet"
+	CheckUnsupportedSkill   = "unsupported_skill"
+	MaxScore                = 100
+	maxBulletCharacters     = 200
+	maxBulletWords          = 30
+	missingDatePenalty      = 10
+	missingMonthPenalty     = 3
+	overlappingDatesPenalty = 5
+	noActionVerbPenalty     = 2
+	longBulletPenalty       = 2
+	unsupportedSkillPenalty = 3
+)
+
+// Finding is a single problem with the field it was found in, written as a path into the user such as
+// experience[0].responsibilities[2]. Penalty is how many points it costs the report's score.
+type Finding struct {
+	Check   string `json:"check" xml:"check"`
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+	Penalty int    `json:"penalty" xml:"penalty"`
+}
+
+// Report scores how well the resume will survive an applicant tracking system, from 0 to MaxScore, and lists what cost
+// it points
+type Report struct {
+	Score    int       `json:"score" xml:"score"`
+	Findings []Finding `json:"findings" xml:"findings>finding"`
+}
+
+// actionVerbs are strong openers that don't end in "ed", in the forms used for current and past positions
+var actionVerbs = map[string]bool{
+	"analyze": true, "architect": true, "automate": true, "build": true, "built": true, "coordinate": true,
+	"create": true, "cut": true, "deliver": true, "deploy": true, "design": true, "develop": true, "drive": true,
+	"drove": true, "establish": true, "grew": true, "grow": true, "improve": true, "increase": true, "launch": true,
+	"lead": true, "led": true, "maintain": true, "manage": true, "mentor": true, "migrate": true, "optimize": true,
+	"oversaw": true, "own": true, "ran": true, "reduce": true, "run": true, "scale": true, "ship": true, "sold": true,
+	"spearhead": true, "taught": true, "won": true, "write": true, "wrote": true,
+}
+
+// weakVerbs describe being present rather than what was done, so they don't count as action verbs
+var weakVerbs = map[string]bool{
+	"assisted": true, "handled": true, "helped": true, "involved": true, "participated": true, "tasked": true,
+	"worked": true,
+}
+
+// Check reviews the user for the problems that most often get a resume filtered out by an applicant tracking system
+func Check(user *models.User) *Report {
+	return check(user, time.Now())
+}
+
+func check(user *models.User, now time.Time) *Report {
+	var findings []Finding
+	findings = append(findings, checkDates(user, now)...)
+	findings = append(findings, checkResponsibilities(user)...)
+	findings = append(findings, checkSkills(user)...)
+
+	report := &Report{Score: MaxScore, Findings: []Finding{}}
+	for _, finding := range findings {
+		report.Score -= finding.Penalty
+		report.Findings = append(report.Findings, finding)
+	}
+
+	if report.Score < 0 {
+		report.Score = 0
+	}
+
+	return report
+}
+
+func checkDates(user *models.User, now time.Time) []Finding {
+	var findings []Finding
+	for i, exp := range user.Experience {
+		if exp.StartDate.IsZero() {
+			findings = append(findings, Finding{
+				Check:   CheckMissingDates,
+				Field:   fmt.Sprintf("experience[%d].start_date", i),
+				Message: fmt.Sprintf("%s at %s has no start date", exp.JobTitle, exp.Company),
+				Penalty: missingDatePenalty,
+			})
+		} else if exp.StartDate.Month == 0 {
+			findings = append(findings, Finding{
+				Check:   CheckMissingDates,
+				Field:   fmt.Sprintf("experience[%d].start_date", i),
+				Message: fmt.Sprintf("%s at %s has a start year but no month", exp.JobTitle, exp.Company),
+				Penalty: missingMonthPenalty,
+			})
+		}
+
+		if exp.EndDate != nil && exp.EndDate.Month == 0 {
+			findings = append(findings, Finding{
+				Check:   CheckMissingDates,
+				Field:   fmt.Sprintf("experience[%d].end_date", i),
+				Message: fmt.Sprintf("%s at %s has an end year but no month", exp.JobTitle, exp.Company),
+				Penalty: missingMonthPenalty,
+			})
+		}
+	}
+
+	for i, degree := range user.Degrees {
+		if degree.StartYear == 0 && degree.EndYear == 0 {
+			findings = append(findings, Finding{
+				Check:   CheckMissingDates,
+				Field:   fmt.Sprintf("degrees[%d].end_year", i),
+				Message: fmt.Sprintf("%s in %s at %s has no dates", degree.Degree, degree.Major, degree.School),
+				Penalty: missingDatePenalty,
+			})
+		}
+	}
+
+	for i, cert := range user.Certifications {
+		if cert.DateAchieved.IsZero() {
+			findings = append(findings, Finding{
+				Check:   CheckMissingDates,
+				Field:   fmt.Sprintf("certifications[%d].date_achieved", i),
+				Message: fmt.Sprintf("%s has no date achieved", cert.Name),
+				Penalty: missingDatePenalty,
+			})
+		}
+	}
+
+	for i, a := range user.Experience {
+		for j := i + 1; j < len(user.Experience); j++ {
+			b := user.Experience[j]
+			if a.StartDate.IsZero() || b.StartDate.IsZero() {
+				continue
+			}
+
+			if start(a) < end(b, now) && start(b) < end(a, now) {
+				findings = append(findings, Finding{
+					Check:   CheckOverlappingDates,
+					Field:   fmt.Sprintf("experience[%d]", j),
+					Message: fmt.Sprintf("%s at %s overlaps %s at %s", b.JobTitle, b.Company, a.JobTitle, a.Company),
+					Penalty: overlappingDatesPenalty,
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkResponsibilities(user *models.User) []Finding {
+	var findings []Finding
+	for i, exp := range user.Experience {
+		for j, responsibility := range exp.Responsibilities {
+			field := fmt.Sprintf("experience[%d].responsibilities[%d]", i, j)
+			if !startsWithActionVerb(responsibility) {
+				findings = append(findings, Finding{
+					Check:   CheckNoActionVerb,
+					Field:   field,
+					Message: "Responsibility does not start with an action verb",
+					Penalty: noActionVerbPenalty,
+				})
+			}
+
+			if words := len(strings.Fields(responsibility)); len(responsibility) > maxBulletCharacters || words > maxBulletWords {
+				findings = append(findings, Finding{
+					Check:   CheckLongBullet,
+					Field:   field,
+					Message: fmt.Sprintf("Responsibility is %d words long, keep it under %d", words, maxBulletWords),
+					Penalty: longBulletPenalty,
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkSkills(user *models.User) []Finding {
+	var text []string
+	for _, exp := range user.Experience {
+		text = append(text, exp.JobTitle)
+		text = append(text, exp.Responsibilities...)
+	}
+	experience := strings.Join(text, "\n")
+
+	var findings []Finding
+	for i, skill := range user.Skills {
+		if len(strings.TrimSpace(skill.Name)) == 0 {
+			continue
+		}
+
+		mentioned := regexp.MustCompile(`(?i)(^|[^a-z0-9])` + regexp.QuoteMeta(strings.TrimSpace(skill.Name)) + `($|[^a-z0-9+#])`)
+		if !mentioned.MatchString(experience) {
+			findings = append(findings, Finding{
+				Check:   CheckUnsupportedSkill,
+				Field:   fmt.Sprintf("skills[%d]", i),
+				Message: fmt.Sprintf("%s is not mentioned in any experience", skill.Name),
+				Penalty: unsupportedSkillPenalty,
+			})
+		}
+	}
+
+	return findings
+}
+
+func startsWithActionVerb(responsibility string) bool {
+	fields := strings.Fields(responsibility)
+	if len(fields) == 0 {
+		return false
+	}
+
+	verb := strings.ToLower(strings.Trim(fields[0], ".,;:-*•"))
+	if weakVerbs[verb] {
+		return false
+	}
+
+	if actionVerbs[verb] || strings.HasSuffix(verb, "ed") {
+		return true
+	}
+
+	// Present tense for the third person, such as "builds" or "manages"
+	return actionVerbs[strings.TrimSuffix(verb, "s")] || actionVerbs[strings.TrimSuffix(verb, "es")]
+}
+
+// start and end count months since year 0 so ranges can be compared. Starting a job the month the last one ended
+// isn't an overlap. A position without an end is still ongoing, and one without a month is taken to cover the year.
+func start(exp models.Experience) int {
+	return exp.StartDate.Year*12 + monthIndex(exp.StartDate.Month, 0)
+}
+
+func end(exp models.Experience, now time.Time) int {
+	if exp.EndDate == nil {
+		return now.Year()*12 + int(now.Month()) - 1
+	}
+
+	return exp.EndDate.Year*12 + monthIndex(exp.EndDate.Month, 11)
+}
+
+func monthIndex(month time.Month, fallback int) int {
+	if month == 0 {
+		return fallback
+	}
+
+	return int(month) - 1
+}
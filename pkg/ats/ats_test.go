@@ -0,0 +1,125 @@
+package ats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+var now = time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+func month(year int, month time.Month) models.Date {
+	return models.Date{Year: year, Month: month}
+}
+
+func ended(year int, month time.Month) *models.Date {
+	return &models.Date{Year: year, Month: month}
+}
+
+func findingsFor(report *Report, check string) []Finding {
+	var findings []Finding
+	for _, finding := range report.Findings {
+		if finding.Check == check {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func TestCleanResume(t *testing.T) {
+	user := &models.User{
+		Experience: []models.Experience{
+			{Company: "Co", JobTitle: "SRE", StartDate: month(2021, time.March), Responsibilities: []string{"Built Go services on Lambda", "Leads the on-call rotation"}},
+			{Company: "Other Co", JobTitle: "Intern", StartDate: month(2019, time.June), EndDate: ended(2021, time.March), Responsibilities: []string{"Migrated reports to Python"}},
+		},
+		Degrees: []models.Degree{{Degree: "BS", Major: "CS", School: "State", StartYear: 2015, EndYear: 2019}},
+		Skills:  []models.Skill{{Name: "Go"}, {Name: "Python"}},
+	}
+
+	if report := check(user, now); MaxScore != report.Score || len(report.Findings) != 0 {
+		t.Errorf("Expected a clean resume to score %d, but was %d with %+v", MaxScore, report.Score, report.Findings)
+	}
+}
+
+func TestMissingDates(t *testing.T) {
+	user := &models.User{
+		Experience: []models.Experience{
+			{Company: "Co", JobTitle: "SRE", Responsibilities: []string{"Built things"}},
+			{Company: "Other Co", JobTitle: "Intern", StartDate: models.Date{Year: 2019}, EndDate: &models.Date{Year: 2020}},
+		},
+		Degrees:        []models.Degree{{Degree: "BS"}},
+		Certifications: []models.Certification{{Name: "Some Cert"}},
+	}
+
+	findings := findingsFor(check(user, now), CheckMissingDates)
+	expected := []string{"experience[0].start_date", "experience[1].start_date", "experience[1].end_date", "degrees[0].end_year", "certifications[0].date_achieved"}
+	if len(expected) != len(findings) {
+		t.Fatalf("Expected %d missing date findings, but was %+v", len(expected), findings)
+	}
+
+	for i, field := range expected {
+		if field != findings[i].Field {
+			t.Errorf("Expected finding %d to be for %s, but was %s", i, field, findings[i].Field)
+		}
+	}
+}
+
+func TestOverlappingDates(t *testing.T) {
+	user := &models.User{
+		Experience: []models.Experience{
+			{Company: "Co", JobTitle: "SRE", StartDate: month(2020, time.January)},
+			{Company: "Other Co", JobTitle: "Contractor", StartDate: month(2019, time.June), EndDate: ended(2020, time.March)},
+			{Company: "Old Co", JobTitle: "Intern", StartDate: month(2018, time.January), EndDate: ended(2019, time.May)},
+		},
+	}
+
+	findings := findingsFor(check(user, now), CheckOverlappingDates)
+	if len(findings) != 1 || "experience[1]" != findings[0].Field {
+		t.Errorf("Expected only the contractor role to overlap, but was %+v", findings)
+	}
+}
+
+func TestResponsibilities(t *testing.T) {
+	user := &models.User{
+		Experience: []models.Experience{{
+			Company:   "Co",
+			JobTitle:  "SRE",
+			StartDate: month(2020, time.January),
+			Responsibilities: []string{
+				"Responsible for the deploy pipeline",
+				"Helped the team with incidents",
+				"Manages three engineers",
+				"Reduced costs " + strings.Repeat("by a lot ", 15),
+			},
+		}},
+	}
+
+	report := check(user, now)
+	verbs := findingsFor(report, CheckNoActionVerb)
+	if len(verbs) != 2 || "experience[0].responsibilities[0]" != verbs[0].Field || "experience[0].responsibilities[1]" != verbs[1].Field {
+		t.Errorf("Expected the first two responsibilities to lack action verbs, but was %+v", verbs)
+	}
+
+	long := findingsFor(report, CheckLongBullet)
+	if len(long) != 1 || "experience[0].responsibilities[3]" != long[0].Field {
+		t.Errorf("Expected the last responsibility to be too long, but was %+v", long)
+	}
+
+	if MaxScore-2*noActionVerbPenalty-longBulletPenalty != report.Score {
+		t.Errorf("Expected score to be reduced by the penalties, but was %d", report.Score)
+	}
+}
+
+func TestUnsupportedSkills(t *testing.T) {
+	user := &models.User{
+		Experience: []models.Experience{{Company: "Co", JobTitle: "SRE", StartDate: month(2020, time.January), Responsibilities: []string{"Wrote good C++ tooling"}}},
+		Skills:     []models.Skill{{Name: "Go"}, {Name: "c++"}, {Name: "C"}},
+	}
+
+	findings := findingsFor(check(user, now), CheckUnsupportedSkill)
+	if len(findings) != 2 || "skills[0]" != findings[0].Field || "skills[2]" != findings[1].Field {
+		t.Errorf("Expected Go and C to be unsupported, but was %+v", findings)
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	ErrorMalformedApiKey = "API key is malformed"
+
+	MethodApiKey = "api_key"
+
+	ApiKeyPrefix    = "rk"
+	ScopeReadResume = "read:resume"
+	ScopeReadSearch = "read:search"
+)
+
+// ApiKeyScopes are the scopes an API key can be issued with. Keys are read-only, so every scope is a read scope.
+var ApiKeyScopes = []string{ScopeReadResume, ScopeReadSearch}
+
+// NewApiKey generates a key with a random secret for keyId, or for a new random ID when keyId is empty, so rotating
+// a key can keep its ID. It returns the full key handed to the integrator along with the hash of its secret, which is
+// all that should be stored.
+func NewApiKey(keyId string) (id string, key string, secretHash string, err error) {
+	if len(keyId) == 0 {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", "", "", err
+		}
+		keyId = hex.EncodeToString(b)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	secret := hex.EncodeToString(b)
+
+	return keyId, strings.Join([]string{ApiKeyPrefix, keyId, secret}, "_"), HashApiKeySecret(secret), nil
+}
+
+// ParseApiKey splits a key made by NewApiKey back into its ID and secret
+func ParseApiKey(key string) (keyId string, secret string, err error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != ApiKeyPrefix || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return "", "", errors.New(ErrorMalformedApiKey)
+	}
+
+	return parts[1], parts[2], nil
+}
+
+func HashApiKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func VerifyApiKeySecret(secret string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashApiKeySecret(secret)), []byte(hash)) == 1
+}
+
+func IsApiKeyScope(scope string) bool {
+	return contains(ApiKeyScopes, scope)
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestApiKey(t *testing.T) {
+	keyId, key, hash, err := NewApiKey("")
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %s", err.Error())
+	}
+
+	if parsedId, secret, err := ParseApiKey(key); err != nil {
+		t.Errorf("Failed to parse API key: %s", err.Error())
+	} else if keyId != parsedId {
+		t.Errorf("Expected key ID to be '%s', but was '%s'", keyId, parsedId)
+	} else if !VerifyApiKeySecret(secret, hash) {
+		t.Errorf("Expected secret to match its hash")
+	}
+
+	rotatedId, rotated, rotatedHash, _ := NewApiKey(keyId)
+	if keyId != rotatedId || key == rotated || hash == rotatedHash {
+		t.Errorf("Expected a rotated key to keep its ID with a new secret")
+	}
+
+	for _, malformed := range []string{"", "rk_abc", "xx_abc_def", "rk__def"} {
+		if _, _, err := ParseApiKey(malformed); err == nil {
+			t.Errorf("Expected '%s' to be rejected", malformed)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// ErrorUnauthorized is the exact message API Gateway expects from an authorizer to answer with a 401
+	ErrorUnauthorized = "Unauthorized"
+)
+
+// AuthorizerRequest accepts both TOKEN and REQUEST authorizer events. TOKEN events carry the Authorization header in
+// AuthorizationToken, while REQUEST events pass the headers through.
+type AuthorizerRequest struct {
+	events.APIGatewayCustomAuthorizerRequest
+	Headers map[string]string `json:"headers"`
+}
+
+func (r AuthorizerRequest) token() string {
+	header := r.AuthorizationToken
+	if len(header) == 0 {
+		for name, value := range r.Headers {
+			if strings.EqualFold(name, "Authorization") {
+				header = value
+			}
+		}
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
+
+// Authorize validates the bearer token on an authorizer event and allows the caller into the whole API stage, with the
+// principal in the context so the API can use it without validating the token again. Any failure is reported as
+// ErrorUnauthorized, which API Gateway turns into a 401.
+func (v *Validator) Authorize(req AuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	token := req.token()
+	if len(token) == 0 {
+		return events.APIGatewayCustomAuthorizerResponse{}, errors.New(ErrorUnauthorized)
+	}
+
+	principal, err := v.Validate(token)
+	if err != nil {
+		return events.APIGatewayCustomAuthorizerResponse{}, errors.New(ErrorUnauthorized)
+	}
+
+	// Authorizer context values can only be strings, numbers or booleans, so the raw claims are left out
+	ctx := principal.AuthorizerContext()
+	delete(ctx, ContextKeyClaims)
+
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principal.Subject,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   "Allow",
+					Resource: []string{stageArn(req.MethodArn)},
+				},
+			},
+		},
+		Context: ctx,
+	}, nil
+}
+
+// stageArn widens a method ARN (arn:aws:execute-api:region:account:api/stage/METHOD/path) to every method and path in
+// the stage, so a cached policy isn't only valid for the route that happened to be called first
+func stageArn(methodArn string) string {
+	parts := strings.SplitN(methodArn, "/", 3)
+	if len(parts) < 2 {
+		return methodArn
+	}
+
+	return parts[0] + "/" + parts[1] + "/*"
+}
@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	ErrorInvalidAudience  = "token audience is not accepted"
+	ErrorInvalidIssuer    = "token issuer is not accepted"
+	ErrorInvalidSignature = "token signature is invalid"
+	ErrorMalformedToken   = "token is malformed"
+	ErrorMissingSubject   = "token does not have a subject"
+	ErrorTokenExpired     = "token has expired"
+	ErrorTokenNotYetValid = "token is not valid yet"
+	ErrorUnknownKey       = "token is signed with an unknown key"
+	ErrorUnsupportedAlg   = "token signing algorithm is not supported"
+
+	DefaultRolesClaim    = "roles"
+	DefaultTenantIdClaim = "tenant_id"
+	DefaultUserIdClaim   = "sub"
+)
+
+type Config struct {
+	JWKSFile      string
+	JWKSURL       string
+	Issuer        string
+	Audience      string
+	UserIdClaim   string
+	RolesClaim    string
+	TenantIdClaim string
+	Leeway        time.Duration
+}
+
+// Validator verifies RS256 and ES256 signed JWTs against a key set and turns their claims into a Principal
+type Validator struct {
+	keys          KeySet
+	issuer        string
+	audience      string
+	userIdClaim   string
+	rolesClaim    string
+	tenantIdClaim string
+	leeway        time.Duration
+	now           func() time.Time
+}
+
+// NewValidator loads the key set from the local file when one is configured, falling back to fetching it from the URL
+func NewValidator(cfg Config) (*Validator, error) {
+	var keys KeySet
+	var err error
+	if len(cfg.JWKSFile) > 0 {
+		keys, err = LoadJWKSFile(cfg.JWKSFile)
+	} else if len(cfg.JWKSURL) > 0 {
+		keys, err = FetchJWKS(cfg.JWKSURL)
+	} else {
+		err = errors.New("either a JWKS file or URL must be configured")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewValidatorWithKeys(keys, cfg), nil
+}
+
+func NewValidatorWithKeys(keys KeySet, cfg Config) *Validator {
+	v := &Validator{
+		keys:          keys,
+		issuer:        cfg.Issuer,
+		audience:      cfg.Audience,
+		userIdClaim:   cfg.UserIdClaim,
+		rolesClaim:    cfg.RolesClaim,
+		tenantIdClaim: cfg.TenantIdClaim,
+		leeway:        cfg.Leeway,
+		now:           time.Now,
+	}
+
+	if len(v.userIdClaim) == 0 {
+		v.userIdClaim = DefaultUserIdClaim
+	}
+
+	if len(v.rolesClaim) == 0 {
+		v.rolesClaim = DefaultRolesClaim
+	}
+
+	if len(v.tenantIdClaim) == 0 {
+		v.tenantIdClaim = DefaultTenantIdClaim
+	}
+
+	return v
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (v *Validator) Validate(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New(ErrorMalformedToken)
+	}
+
+	hdr := &header{}
+	if err := decodeSegment(parts[0], hdr); err != nil {
+		return nil, errors.New(ErrorMalformedToken)
+	}
+
+	key, err := v.key(hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New(ErrorMalformedToken)
+	}
+
+	if err := verifySignature(hdr.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.New(ErrorMalformedToken)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return v.principal(claims)
+}
+
+func (v *Validator) key(kid string) (crypto.PublicKey, error) {
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	// Tokens without a kid are only accepted when there is no ambiguity about which key signed them
+	if len(kid) == 0 && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New(ErrorUnknownKey)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	hash := sha256.Sum256([]byte(signed))
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New(ErrorUnsupportedAlg)
+		}
+
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash[:], signature); err != nil {
+			return errors.New(ErrorInvalidSignature)
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New(ErrorUnsupportedAlg)
+		}
+
+		if len(signature) != 64 {
+			return errors.New(ErrorInvalidSignature)
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, hash[:], r, s) {
+			return errors.New(ErrorInvalidSignature)
+		}
+	default:
+		return errors.New(ErrorUnsupportedAlg)
+	}
+
+	return nil
+}
+
+func (v *Validator) validateClaims(claims map[string]interface{}) error {
+	now := v.now()
+	exp, ok := numericClaim(claims, "exp")
+	if !ok || now.After(time.Unix(exp, 0).Add(v.leeway)) {
+		return errors.New(ErrorTokenExpired)
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(v.leeway).Before(time.Unix(nbf, 0)) {
+		return errors.New(ErrorTokenNotYetValid)
+	}
+
+	if len(v.issuer) > 0 {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return errors.New(ErrorInvalidIssuer)
+		}
+	}
+
+	if len(v.audience) > 0 && !contains(stringsClaim(claims, "aud"), v.audience) {
+		return errors.New(ErrorInvalidAudience)
+	}
+
+	return nil
+}
+
+func (v *Validator) principal(claims map[string]interface{}) (*Principal, error) {
+	subject, _ := claims["sub"].(string)
+	if len(subject) == 0 {
+		return nil, errors.New(ErrorMissingSubject)
+	}
+
+	userId, _ := claims[v.userIdClaim].(string)
+	if len(userId) == 0 {
+		userId = subject
+	}
+
+	tenantId, _ := claims[v.tenantIdClaim].(string)
+
+	return &Principal{
+		Subject:  subject,
+		UserId:   userId,
+		TenantId: tenantId,
+		Roles:    stringsClaim(claims, v.rolesClaim),
+		Method:   MethodJWT,
+		Claims:   claims,
+	}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func numericClaim(claims map[string]interface{}, name string) (int64, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	if value, err := number.Int64(); err == nil {
+		return value, true
+	}
+
+	value, err := number.Float64()
+	return int64(value), err == nil
+}
+
+// stringsClaim accepts a claim written as a single string, a space separated string (as OAuth scopes are) or an array
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
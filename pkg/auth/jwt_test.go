@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+var now = time.Unix(1600000000, 0)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal token segment: %s", err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signToken(t *testing.T, hdr map[string]string, claims map[string]interface{}, key crypto.Signer) string {
+	signed := encodeSegment(t, hdr) + "." + encodeSegment(t, claims)
+	hash := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %s", err.Error())
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %s", err.Error())
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	jwks := JWKS{Keys: []JWK{
+		{Kty: "RSA", Kid: "rsa1", Use: "sig", N: encode(rsaKey.N), E: encode(big.NewInt(int64(rsaKey.E)))},
+		{Kty: "EC", Kid: "ec1", Crv: "P-256", X: encode(ecKey.X), Y: encode(ecKey.Y)},
+		{Kty: "RSA", Kid: "enc1", Use: "enc", N: encode(rsaKey.N), E: encode(big.NewInt(int64(rsaKey.E)))},
+	}}
+
+	file, err := ioutil.TempFile("", "jwks-*.json")
+	if err != nil {
+		t.Fatalf("Failed to create JWKS file: %s", err.Error())
+	}
+	defer file.Close()
+	t.Cleanup(func() { _ = os.Remove(file.Name()) })
+
+	if err := json.NewEncoder(file).Encode(jwks); err != nil {
+		t.Fatalf("Failed to write JWKS file: %s", err.Error())
+	}
+
+	return file.Name()
+}
+
+func setup(t *testing.T) (*Validator, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %s", err.Error())
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %s", err.Error())
+	}
+
+	v, err := NewValidator(Config{
+		JWKSFile:    writeJWKS(t, rsaKey, ecKey),
+		Issuer:      "https://issuer/",
+		Audience:    "resume-api",
+		UserIdClaim: "https://resume/user_id",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create validator: %s", err.Error())
+	}
+	v.now = func() time.Time { return now }
+
+	return v, rsaKey, ecKey
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":                    "auth0|123",
+		"iss":                    "https://issuer/",
+		"aud":                    []string{"resume-api", "other"},
+		"exp":                    now.Add(time.Hour).Unix(),
+		"roles":                  []string{"admin"},
+		"tenant_id":              "tenant1",
+		"https://resume/user_id": "user1",
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	if _, err := ParseJWKS([]byte(`{"keys": [{"kty": "oct", "kid": "hmac"}]}`)); err == nil {
+		t.Errorf("Expected a key set without usable keys to fail")
+	} else if ErrorNoKeys != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorNoKeys, err.Error())
+	}
+
+	if _, err := ParseJWKS([]byte(`{"keys": [{"kty": "EC", "kid": "ec1", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`)); err == nil {
+		t.Errorf("Expected a point off the curve to fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, rsaKey, ecKey := setup(t)
+
+	for kid, key := range map[string]crypto.Signer{"rsa1": rsaKey, "ec1": ecKey} {
+		alg := "RS256"
+		if kid == "ec1" {
+			alg = "ES256"
+		}
+
+		token := signToken(t, map[string]string{"alg": alg, "kid": kid}, validClaims(), key)
+		if principal, err := v.Validate(token); err != nil {
+			t.Errorf("Failed to validate %s token: %s", alg, err.Error())
+		} else {
+			if "auth0|123" != principal.Subject {
+				t.Errorf("Expected subject to be 'auth0|123', but was '%s'", principal.Subject)
+			}
+
+			if "user1" != principal.UserId {
+				t.Errorf("Expected user ID to be 'user1', but was '%s'", principal.UserId)
+			}
+
+			if "tenant1" != principal.TenantId {
+				t.Errorf("Expected tenant ID to be 'tenant1', but was '%s'", principal.TenantId)
+			}
+
+			if !principal.HasRole("admin") {
+				t.Errorf("Expected principal to have the admin role, but had %v", principal.Roles)
+			}
+
+			if MethodJWT != principal.Method {
+				t.Errorf("Expected method to be '%s', but was '%s'", MethodJWT, principal.Method)
+			}
+		}
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	v, rsaKey, ecKey := setup(t)
+	rsaHeader := map[string]string{"alg": "RS256", "kid": "rsa1"}
+
+	expired := validClaims()
+	expired["exp"] = now.Add(-time.Minute).Unix()
+
+	notYetValid := validClaims()
+	notYetValid["nbf"] = now.Add(time.Minute).Unix()
+
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "other"
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://attacker/"
+
+	noSubject := validClaims()
+	delete(noSubject, "sub")
+
+	valid := signToken(t, rsaHeader, validClaims(), rsaKey)
+	unsigned := encodeSegment(t, map[string]string{"alg": "none", "kid": "rsa1"}) + "." + encodeSegment(t, validClaims()) + "."
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := map[string]struct {
+		token string
+		err   string
+	}{
+		"expired":            {signToken(t, rsaHeader, expired, rsaKey), ErrorTokenExpired},
+		"not yet valid":      {signToken(t, rsaHeader, notYetValid, rsaKey), ErrorTokenNotYetValid},
+		"no expiry":          {signToken(t, rsaHeader, noExpiry, rsaKey), ErrorTokenExpired},
+		"wrong audience":     {signToken(t, rsaHeader, wrongAudience, rsaKey), ErrorInvalidAudience},
+		"wrong issuer":       {signToken(t, rsaHeader, wrongIssuer, rsaKey), ErrorInvalidIssuer},
+		"no subject":         {signToken(t, rsaHeader, noSubject, rsaKey), ErrorMissingSubject},
+		"unknown key":        {signToken(t, map[string]string{"alg": "RS256", "kid": "rsa2"}, validClaims(), rsaKey), ErrorUnknownKey},
+		"ambiguous key":      {signToken(t, map[string]string{"alg": "RS256"}, validClaims(), rsaKey), ErrorUnknownKey},
+		"encryption key":     {signToken(t, map[string]string{"alg": "RS256", "kid": "enc1"}, validClaims(), rsaKey), ErrorUnknownKey},
+		"other signer":       {signToken(t, rsaHeader, validClaims(), otherKey), ErrorInvalidSignature},
+		"algorithm mismatch": {signToken(t, map[string]string{"alg": "ES256", "kid": "rsa1"}, validClaims(), ecKey), ErrorUnsupportedAlg},
+		"alg none":           {unsigned, ErrorUnsupportedAlg},
+		"tampered":           {valid[:len(valid)-4] + "AAAA", ErrorInvalidSignature},
+		"malformed":          {"not-a-token", ErrorMalformedToken},
+	}
+
+	for name, test := range tests {
+		if _, err := v.Validate(test.token); err == nil {
+			t.Errorf("Expected %s token to be rejected", name)
+		} else if test.err != err.Error() {
+			t.Errorf("Expected error for %s token to be '%s', but was '%s'", name, test.err, err.Error())
+		}
+	}
+}
+
+func TestPrincipalFromAuthorizer(t *testing.T) {
+	principal := &Principal{Subject: "auth0|123", UserId: "user1", TenantId: "tenant1", Roles: []string{"admin", "editor"}, Method: MethodJWT}
+
+	if p, ok := PrincipalFromAuthorizer(principal.AuthorizerContext()); !ok {
+		t.Errorf("Expected a principal to be read back from the authorizer context")
+	} else if principal.Subject != p.Subject || principal.UserId != p.UserId || principal.TenantId != p.TenantId || !p.HasRole("editor") {
+		t.Errorf("Expected principal to be %+v, but was %+v", principal, p)
+	}
+
+	if _, ok := PrincipalFromAuthorizer(map[string]interface{}{}); ok {
+		t.Errorf("Expected no principal from an empty authorizer context")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	v, rsaKey, _ := setup(t)
+	token := signToken(t, map[string]string{"alg": "RS256", "kid": "rsa1"}, validClaims(), rsaKey)
+	methodArn := "arn:aws:execute-api:us-east-2:123456789012:abcdef/v1/DELETE/user/user1"
+
+	tokenReq := AuthorizerRequest{}
+	tokenReq.Type = "TOKEN"
+	tokenReq.AuthorizationToken = "Bearer " + token
+	tokenReq.MethodArn = methodArn
+
+	requestReq := AuthorizerRequest{Headers: map[string]string{"authorization": "Bearer " + token}}
+	requestReq.Type = "REQUEST"
+	requestReq.MethodArn = methodArn
+
+	for _, req := range []AuthorizerRequest{tokenReq, requestReq} {
+		resp, err := v.Authorize(req)
+		if err != nil {
+			t.Errorf("Failed to authorize %s request: %s", req.Type, err.Error())
+			continue
+		}
+
+		if "auth0|123" != resp.PrincipalID {
+			t.Errorf("Expected principal ID to be 'auth0|123', but was '%s'", resp.PrincipalID)
+		}
+
+		statement := resp.PolicyDocument.Statement[0]
+		if "Allow" != statement.Effect || "arn:aws:execute-api:us-east-2:123456789012:abcdef/v1/*" != statement.Resource[0] {
+			t.Errorf("Expected the policy to allow the whole stage, but was %+v", statement)
+		}
+
+		if _, ok := resp.Context[ContextKeyClaims]; ok {
+			t.Errorf("Expected claims to be left out of the authorizer context")
+		}
+
+		if principal, ok := PrincipalFromAuthorizer(map[string]interface{}{"lambda": resp.Context}); !ok || "user1" != principal.UserId {
+			t.Errorf("Expected the API to read user 'user1' from the authorizer context, but was %+v", principal)
+		}
+	}
+
+	tokenReq.AuthorizationToken = "Bearer " + token + "x"
+	if _, err := v.Authorize(tokenReq); err == nil || ErrorUnauthorized != err.Error() {
+		t.Errorf("Expected an invalid token to be unauthorized")
+	}
+
+	tokenReq.AuthorizationToken = ""
+	if _, err := v.Authorize(tokenReq); err == nil || ErrorUnauthorized != err.Error() {
+		t.Errorf("Expected a missing token to be unauthorized")
+	}
+}
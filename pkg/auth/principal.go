@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"strings"
+)
+
+const (
+	MethodJWT = "jwt"
+
+	// RoleAdmin may modify any user's resume
+	RoleAdmin = "admin"
+
+	// Keys the principal is stored under in the API Gateway authorizer context
+	ContextKeyMethod   = "auth_method"
+	ContextKeyRoles    = "roles"
+	ContextKeyScopes   = "scopes"
+	ContextKeySubject  = "principalId"
+	ContextKeyTenantId = "tenant_id"
+	ContextKeyUserId   = "user_id"
+	ContextKeyClaims   = "claims"
+
+	// ContextKeyLambda is where HTTP APIs nest the context returned by a Lambda authorizer
+	ContextKeyLambda = "lambda"
+)
+
+// ContextKeys are all the keys a principal is stored under, so untrusted values can be cleared before one is set
+var ContextKeys = []string{ContextKeyMethod, ContextKeyRoles, ContextKeyScopes, ContextKeySubject, ContextKeyTenantId, ContextKeyUserId, ContextKeyClaims, ContextKeyLambda}
+
+// Principal is the authenticated caller. UserId is the resume user the caller acts as, which defaults to the token
+// subject but can be mapped from another claim. TenantId is empty for callers who don't belong to a tenant.
+type Principal struct {
+	Subject  string
+	UserId   string
+	TenantId string
+	Roles    []string
+	Scopes   []string
+	Method   string
+	Claims   map[string]interface{}
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// AuthorizerContext flattens the principal into the shape API Gateway uses for authorizer context, so a principal
+// reads the same whether it was set by this function or by an authorizer in front of it
+func (p *Principal) AuthorizerContext() map[string]interface{} {
+	ctx := map[string]interface{}{
+		ContextKeySubject: p.Subject,
+		ContextKeyUserId:  p.UserId,
+		ContextKeyRoles:   strings.Join(p.Roles, ","),
+		ContextKeyMethod:  p.Method,
+	}
+
+	if len(p.TenantId) > 0 {
+		ctx[ContextKeyTenantId] = p.TenantId
+	}
+
+	if len(p.Scopes) > 0 {
+		ctx[ContextKeyScopes] = strings.Join(p.Scopes, " ")
+	}
+
+	if p.Claims != nil {
+		ctx[ContextKeyClaims] = p.Claims
+	}
+
+	return ctx
+}
+
+// PrincipalFromAuthorizer is the inverse of AuthorizerContext, returning false when there is no authenticated caller
+func PrincipalFromAuthorizer(ctx map[string]interface{}) (*Principal, bool) {
+	if nested, ok := ctx[ContextKeyLambda].(map[string]interface{}); ok {
+		ctx = nested
+	}
+
+	subject, _ := ctx[ContextKeySubject].(string)
+	if len(subject) == 0 {
+		return nil, false
+	}
+
+	p := &Principal{Subject: subject}
+	p.UserId, _ = ctx[ContextKeyUserId].(string)
+	p.TenantId, _ = ctx[ContextKeyTenantId].(string)
+	p.Method, _ = ctx[ContextKeyMethod].(string)
+	p.Claims, _ = ctx[ContextKeyClaims].(map[string]interface{})
+	if roles, _ := ctx[ContextKeyRoles].(string); len(roles) > 0 {
+		p.Roles = strings.Split(roles, ",")
+	}
+	if scopes, _ := ctx[ContextKeyScopes].(string); len(scopes) > 0 {
+		p.Scopes = strings.Fields(scopes)
+	}
+
+	if len(p.UserId) == 0 {
+		p.UserId = subject
+	}
+
+	return p, true
+}
@@ -0,0 +1,425 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/storage"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	BackendDynamoDB = "dynamodb"
+
+	// FileEnv names the optional config file. Environment variables override anything set in it.
+	FileEnv = "CONFIG_FILE"
+)
+
+// Config is everything the API needs to start. It is loaded once, and every problem with it is reported together
+// rather than failing on the first request that happens to use a bad setting.
+type Config struct {
+	LogLevel string   `yaml:"log_level"`
+	Region   string   `yaml:"region"`
+	Storage  Storage  `yaml:"storage"`
+	Tables   Tables   `yaml:"tables"`
+	Auth     Auth     `yaml:"auth"`
+	Cors     Cors     `yaml:"cors"`
+	Limits   Limits   `yaml:"limits"`
+	Features Features `yaml:"features"`
+}
+
+type Storage struct {
+	Backend          string        `yaml:"backend"`
+	Endpoint         string        `yaml:"endpoint"`
+	MaxAttempts      int           `yaml:"max_attempts"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+type Tables struct {
+	Users         string `yaml:"users"`
+	Resumes       string `yaml:"resumes"`
+	Shares        string `yaml:"shares"`
+	ApiKeys       string `yaml:"api_keys"`
+	Organizations string `yaml:"organizations"`
+	Memberships   string `yaml:"memberships"`
+	Idempotency   string `yaml:"idempotency"`
+}
+
+type Auth struct {
+	JWKSFile         string `yaml:"jwks_file"`
+	JWKSURL          string `yaml:"jwks_url"`
+	Issuer           string `yaml:"issuer"`
+	Audience         string `yaml:"audience"`
+	UserIdClaim      string `yaml:"user_id_claim"`
+	RolesClaim       string `yaml:"roles_claim"`
+	TenantIdClaim    string `yaml:"tenant_id_claim"`
+	TrustAuthorizer  bool   `yaml:"trust_authorizer"`
+	ShareTokenSecret string `yaml:"share_token_secret"`
+}
+
+type Cors struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+type Limits struct {
+	MaxBodyBytes int `yaml:"max_body_bytes"`
+	MaxBodyDepth int `yaml:"max_body_depth"`
+}
+
+// Features turn whole parts of the API on or off. Everything is on unless it is turned off.
+type Features struct {
+	Idempotency    bool          `yaml:"idempotency"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+	Shares         bool          `yaml:"shares"`
+	ApiKeys        bool          `yaml:"api_keys"`
+	LinkedinImport bool          `yaml:"linkedin_import"`
+	Tailor         bool          `yaml:"tailor"`
+}
+
+// Error lists every problem found with the configuration, so they can all be fixed at once
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func Default() *Config {
+	return &Config{
+		LogLevel: "info",
+		Storage: Storage{
+			Backend:          BackendDynamoDB,
+			MaxAttempts:      storage.DefaultMaxAttempts,
+			FailureThreshold: storage.DefaultFailureThreshold,
+			Cooldown:         storage.DefaultCooldown,
+		},
+		Tables: Tables{
+			Users:         "resume_user",
+			Resumes:       "resume_resume",
+			Shares:        "resume_share",
+			ApiKeys:       "resume_api_key",
+			Organizations: "resume_org",
+			Memberships:   "resume_membership",
+			Idempotency:   "resume_idempotency",
+		},
+		Limits: Limits{
+			MaxBodyBytes: 1 << 20,
+			MaxBodyDepth: 32,
+		},
+		Features: Features{
+			Idempotency:    true,
+			IdempotencyTTL: 24 * time.Hour,
+			Shares:         true,
+			ApiKeys:        true,
+			LinkedinImport: true,
+			Tailor:         true,
+		},
+	}
+}
+
+// Load reads the config file named by CONFIG_FILE when there is one, applies the environment on top of it and
+// validates the result
+func Load() (*Config, error) {
+	return load(os.LookupEnv)
+}
+
+// LoadAuth is Load for the authorizer, which only validates bearer tokens and so only needs the log level and the
+// token settings to be valid
+func LoadAuth() (*Config, error) {
+	return loadAuth(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (*Config, error) {
+	cfg, problems := read(lookup)
+	if problems = append(problems, cfg.validate()...); len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+func loadAuth(lookup func(string) (string, bool)) (*Config, error) {
+	cfg, problems := read(lookup)
+	problems = append(problems, cfg.validateLogLevel()...)
+	if problems = append(problems, cfg.validateAuth()...); len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+// read applies the file and then the environment over the defaults, returning the settings that couldn't be parsed
+func read(lookup func(string) (string, bool)) (*Config, []string) {
+	cfg := Default()
+	env := &envReader{lookup: lookup}
+	if path, ok := lookup(FileEnv); ok && len(path) > 0 {
+		if err := cfg.readFile(path); err != nil {
+			env.problems = append(env.problems, err.Error())
+		}
+	}
+
+	env.string("LOG_LEVEL", &cfg.LogLevel)
+	env.string("AWS_REGION", &cfg.Region)
+
+	env.string("STORAGE_BACKEND", &cfg.Storage.Backend)
+	env.string("DYNAMODB_ENDPOINT", &cfg.Storage.Endpoint)
+	env.int("STORAGE_MAX_ATTEMPTS", &cfg.Storage.MaxAttempts)
+	env.int("STORAGE_FAILURE_THRESHOLD", &cfg.Storage.FailureThreshold)
+	env.duration("STORAGE_COOLDOWN", &cfg.Storage.Cooldown)
+
+	env.string("USERS_TABLE", &cfg.Tables.Users)
+	env.string("RESUMES_TABLE", &cfg.Tables.Resumes)
+	env.string("SHARES_TABLE", &cfg.Tables.Shares)
+	env.string("API_KEYS_TABLE", &cfg.Tables.ApiKeys)
+	env.string("ORGANIZATIONS_TABLE", &cfg.Tables.Organizations)
+	env.string("MEMBERSHIPS_TABLE", &cfg.Tables.Memberships)
+	env.string("IDEMPOTENCY_TABLE", &cfg.Tables.Idempotency)
+
+	env.string("JWKS_FILE", &cfg.Auth.JWKSFile)
+	env.string("JWKS_URL", &cfg.Auth.JWKSURL)
+	env.string("JWT_ISSUER", &cfg.Auth.Issuer)
+	env.string("JWT_AUDIENCE", &cfg.Auth.Audience)
+	env.string("JWT_USER_ID_CLAIM", &cfg.Auth.UserIdClaim)
+	env.string("JWT_ROLES_CLAIM", &cfg.Auth.RolesClaim)
+	env.string("JWT_TENANT_ID_CLAIM", &cfg.Auth.TenantIdClaim)
+	env.bool("TRUST_AUTHORIZER_CONTEXT", &cfg.Auth.TrustAuthorizer)
+	env.string("SHARE_TOKEN_SECRET", &cfg.Auth.ShareTokenSecret)
+
+	env.list("CORS_ALLOWED_ORIGINS", &cfg.Cors.AllowedOrigins)
+	env.bool("CORS_ALLOW_CREDENTIALS", &cfg.Cors.AllowCredentials)
+	env.duration("CORS_MAX_AGE", &cfg.Cors.MaxAge)
+
+	env.int("MAX_BODY_BYTES", &cfg.Limits.MaxBodyBytes)
+	env.int("MAX_BODY_DEPTH", &cfg.Limits.MaxBodyDepth)
+
+	env.bool("FEATURE_IDEMPOTENCY", &cfg.Features.Idempotency)
+	env.duration("IDEMPOTENCY_TTL", &cfg.Features.IdempotencyTTL)
+	env.bool("FEATURE_SHARES", &cfg.Features.Shares)
+	env.bool("FEATURE_API_KEYS", &cfg.Features.ApiKeys)
+	env.bool("FEATURE_LINKEDIN_IMPORT", &cfg.Features.LinkedinImport)
+	env.bool("FEATURE_TAILOR", &cfg.Features.Tailor)
+
+	return cfg, env.problems
+}
+
+// readFile is strict about field names, so a misspelled setting is reported instead of quietly left at its default
+func (c *Config) readFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) validate() []string {
+	problems := c.validateLogLevel()
+	missing := func(name string, value string) {
+		if len(value) == 0 {
+			problems = append(problems, name+" is required")
+		}
+	}
+
+	missing("AWS_REGION", c.Region)
+	if c.Storage.Backend != BackendDynamoDB {
+		problems = append(problems, fmt.Sprintf("STORAGE_BACKEND '%s' is not supported, the only backend is '%s'", c.Storage.Backend, BackendDynamoDB))
+	}
+	if len(c.Storage.Endpoint) > 0 {
+		if u, err := url.Parse(c.Storage.Endpoint); err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			problems = append(problems, fmt.Sprintf("DYNAMODB_ENDPOINT '%s' must be an absolute URL", c.Storage.Endpoint))
+		}
+	}
+	if c.Storage.MaxAttempts < 1 {
+		problems = append(problems, "STORAGE_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Storage.FailureThreshold < 1 {
+		problems = append(problems, "STORAGE_FAILURE_THRESHOLD must be at least 1")
+	}
+	if c.Storage.Cooldown <= 0 {
+		problems = append(problems, "STORAGE_COOLDOWN must be positive")
+	}
+
+	missing("USERS_TABLE", c.Tables.Users)
+	missing("RESUMES_TABLE", c.Tables.Resumes)
+	missing("SHARES_TABLE", c.Tables.Shares)
+	missing("API_KEYS_TABLE", c.Tables.ApiKeys)
+	missing("ORGANIZATIONS_TABLE", c.Tables.Organizations)
+	missing("MEMBERSHIPS_TABLE", c.Tables.Memberships)
+	missing("IDEMPOTENCY_TABLE", c.Tables.Idempotency)
+
+	problems = append(problems, c.validateAuth()...)
+	if c.Features.Shares {
+		missing("SHARE_TOKEN_SECRET (or set FEATURE_SHARES=false)", c.Auth.ShareTokenSecret)
+	}
+
+	for _, origin := range c.Cors.AllowedOrigins {
+		if !validOrigin(origin) {
+			problems = append(problems, fmt.Sprintf("CORS_ALLOWED_ORIGINS entry '%s' must be '*' or an origin like https://example.com or https://*.example.com", origin))
+		} else if origin == "*" && c.Cors.AllowCredentials {
+			problems = append(problems, "CORS_ALLOW_CREDENTIALS can't be used with the '*' origin, list the origins instead")
+		}
+	}
+	if c.Cors.MaxAge < 0 {
+		problems = append(problems, "CORS_MAX_AGE can't be negative")
+	}
+
+	if c.Limits.MaxBodyBytes < 1 {
+		problems = append(problems, "MAX_BODY_BYTES must be at least 1")
+	}
+	if c.Limits.MaxBodyDepth < 1 {
+		problems = append(problems, "MAX_BODY_DEPTH must be at least 1")
+	}
+	if c.Features.Idempotency && c.Features.IdempotencyTTL <= 0 {
+		problems = append(problems, "IDEMPOTENCY_TTL must be positive")
+	}
+
+	return problems
+}
+
+func (c *Config) validateLogLevel() []string {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return []string{fmt.Sprintf("LOG_LEVEL '%s' must be one of debug, info, warn or error", c.LogLevel)}
+	}
+
+	return nil
+}
+
+// validateAuth requires everything tokens are checked against, since a validator without an issuer or audience would
+// accept tokens the identity provider minted for any other API
+func (c *Config) validateAuth() []string {
+	var problems []string
+	if len(c.Auth.JWKSFile) == 0 && len(c.Auth.JWKSURL) == 0 {
+		problems = append(problems, "JWKS_FILE or JWKS_URL is required to validate bearer tokens")
+	}
+	if len(c.Auth.Issuer) == 0 {
+		problems = append(problems, "JWT_ISSUER is required")
+	}
+	if len(c.Auth.Audience) == 0 {
+		problems = append(problems, "JWT_AUDIENCE is required")
+	}
+
+	return problems
+}
+
+// validOrigin accepts "*" and scheme://host[:port], where the host may start with a "*." wildcard
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0 && len(u.Path) == 0 && len(u.RawQuery) == 0 && len(u.Fragment) == 0 && u.User == nil && !strings.Contains(u.Host, "*")
+}
+
+func (c *Config) ZapLevel() zapcore.Level {
+	var level zapcore.Level
+	_ = level.UnmarshalText([]byte(c.LogLevel))
+	return level
+}
+
+func (a Auth) Validator() auth.Config {
+	return auth.Config{
+		JWKSFile:      a.JWKSFile,
+		JWKSURL:       a.JWKSURL,
+		Issuer:        a.Issuer,
+		Audience:      a.Audience,
+		UserIdClaim:   a.UserIdClaim,
+		RolesClaim:    a.RolesClaim,
+		TenantIdClaim: a.TenantIdClaim,
+	}
+}
+
+func (s Storage) Resilience() storage.Config {
+	return storage.Config{
+		MaxAttempts:      s.MaxAttempts,
+		FailureThreshold: s.FailureThreshold,
+		Cooldown:         s.Cooldown,
+	}
+}
+
+// envReader applies environment variables that are set, collecting the ones that can't be parsed
+type envReader struct {
+	lookup   func(string) (string, bool)
+	problems []string
+}
+
+func (e *envReader) get(name string) (string, bool) {
+	value, ok := e.lookup(name)
+	return strings.TrimSpace(value), ok
+}
+
+func (e *envReader) string(name string, target *string) {
+	if value, ok := e.get(name); ok {
+		*target = value
+	}
+}
+
+func (e *envReader) list(name string, target *[]string) {
+	value, ok := e.get(name)
+	if !ok {
+		return
+	}
+
+	*target = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			*target = append(*target, item)
+		}
+	}
+}
+
+func (e *envReader) bool(name string, target *bool) {
+	if value, ok := e.get(name); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s '%s' must be true or false", name, value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (e *envReader) int(name string, target *int) {
+	if value, ok := e.get(name); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s '%s' must be a whole number", name, value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (e *envReader) duration(name string, target *time.Duration) {
+	if value, ok := e.get(name); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s '%s' must be a duration like 30s or 24h", name, value))
+			return
+		}
+		*target = parsed
+	}
+}
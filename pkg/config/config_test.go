@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func requiredEnv() map[string]string {
+	return map[string]string{
+		"AWS_REGION":         "us-east-2",
+		"JWKS_URL":           "https://example.com/.well-known/jwks.json",
+		"JWT_ISSUER":         "https://example.com/",
+		"JWT_AUDIENCE":       "resume-api",
+		"SHARE_TOKEN_SECRET": "secret",
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(lookupFrom(requiredEnv()))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %s", err.Error())
+	}
+
+	if "resume_user" != cfg.Tables.Users {
+		t.Errorf("Expected users table to default to 'resume_user', but was '%s'", cfg.Tables.Users)
+	}
+
+	if BackendDynamoDB != cfg.Storage.Backend {
+		t.Errorf("Expected storage backend to default to '%s', but was '%s'", BackendDynamoDB, cfg.Storage.Backend)
+	}
+
+	if !cfg.Features.Idempotency || !cfg.Features.Shares || 24*time.Hour != cfg.Features.IdempotencyTTL {
+		t.Errorf("Expected every feature to be on by default, but was %+v", cfg.Features)
+	}
+
+	if "resume-api" != cfg.Auth.Validator().Audience {
+		t.Errorf("Expected validator audience to be 'resume-api', but was '%s'", cfg.Auth.Validator().Audience)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	env := requiredEnv()
+	env["USERS_TABLE"] = "dev_user"
+	env["LOG_LEVEL"] = "debug"
+	env["CORS_ALLOWED_ORIGINS"] = "https://example.com, https://*.example.org"
+	env["CORS_ALLOW_CREDENTIALS"] = "true"
+	env["CORS_MAX_AGE"] = "10m"
+	env["STORAGE_COOLDOWN"] = "1m"
+	env["FEATURE_SHARES"] = "false"
+	delete(env, "SHARE_TOKEN_SECRET")
+
+	cfg, err := load(lookupFrom(env))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %s", err.Error())
+	}
+
+	if "dev_user" != cfg.Tables.Users {
+		t.Errorf("Expected users table to be 'dev_user', but was '%s'", cfg.Tables.Users)
+	}
+
+	if "debug" != cfg.ZapLevel().String() {
+		t.Errorf("Expected log level to be debug, but was %s", cfg.ZapLevel())
+	}
+
+	expectedOrigins := []string{"https://example.com", "https://*.example.org"}
+	if !reflect.DeepEqual(expectedOrigins, cfg.Cors.AllowedOrigins) || !cfg.Cors.AllowCredentials || 10*time.Minute != cfg.Cors.MaxAge {
+		t.Errorf("Expected CORS to be read from the environment, but was %+v", cfg.Cors)
+	}
+
+	if time.Minute != cfg.Storage.Resilience().Cooldown {
+		t.Errorf("Expected storage cooldown to be a minute, but was %s", cfg.Storage.Resilience().Cooldown)
+	}
+
+	if cfg.Features.Shares {
+		t.Errorf("Expected shares to be turned off")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`region: eu-west-1
+tables:
+  users: file_user
+features:
+  tailor: false
+  idempotency_ttl: 1h
+`)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err.Error())
+	}
+
+	env := requiredEnv()
+	env[FileEnv] = path
+	delete(env, "AWS_REGION")
+	env["USERS_TABLE"] = "env_user"
+
+	cfg, err := load(lookupFrom(env))
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %s", err.Error())
+	}
+
+	if "eu-west-1" != cfg.Region {
+		t.Errorf("Expected region to come from the file, but was '%s'", cfg.Region)
+	}
+
+	if "env_user" != cfg.Tables.Users {
+		t.Errorf("Expected the environment to override the file, but users table was '%s'", cfg.Tables.Users)
+	}
+
+	if "resume_resume" != cfg.Tables.Resumes {
+		t.Errorf("Expected settings missing from the file to keep their defaults, but resumes table was '%s'", cfg.Tables.Resumes)
+	}
+
+	if cfg.Features.Tailor || !cfg.Features.Shares || time.Hour != cfg.Features.IdempotencyTTL {
+		t.Errorf("Expected features to be read from the file, but was %+v", cfg.Features)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("region: eu-west-1\ntabels:\n  users: file_user\ncors:\n  max_age: 10m\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err.Error())
+	}
+	env["MAX_BODY_BYTES"] = "lots"
+	_, err = load(lookupFrom(env))
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems) != 2 {
+		t.Errorf("Expected problems with the file and the environment to be reported together, but error was %v", err)
+	} else if !strings.Contains(cfgErr.Problems[0], "tabels") || !strings.Contains(cfgErr.Problems[1], "MAX_BODY_BYTES") {
+		t.Errorf("Expected a misspelled setting and a bad number to be reported, but problems were %v", cfgErr.Problems)
+	}
+	delete(env, "MAX_BODY_BYTES")
+
+	env[FileEnv] = filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := load(lookupFrom(env)); err == nil {
+		t.Errorf("Expected a missing config file to be an error")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	env := map[string]string{
+		"LOG_LEVEL":              "loud",
+		"STORAGE_BACKEND":        "postgres",
+		"USERS_TABLE":            "",
+		"CORS_ALLOWED_ORIGINS":   "*,example.com,https://example.com/path",
+		"CORS_ALLOW_CREDENTIALS": "yes please",
+		"MAX_BODY_BYTES":         "lots",
+		"FEATURE_SHARES":         "true",
+	}
+
+	_, err := load(lookupFrom(env))
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected a configuration error, but was %v", err)
+	}
+
+	expected := []string{
+		"CORS_ALLOW_CREDENTIALS 'yes please' must be true or false",
+		"MAX_BODY_BYTES 'lots' must be a whole number",
+		"LOG_LEVEL 'loud' must be one of debug, info, warn or error",
+		"AWS_REGION is required",
+		"STORAGE_BACKEND 'postgres' is not supported, the only backend is 'dynamodb'",
+		"USERS_TABLE is required",
+		"JWKS_FILE or JWKS_URL is required to validate bearer tokens",
+		"JWT_ISSUER is required",
+		"JWT_AUDIENCE is required",
+		"SHARE_TOKEN_SECRET (or set FEATURE_SHARES=false) is required",
+		"CORS_ALLOWED_ORIGINS entry 'example.com' must be '*' or an origin like https://example.com or https://*.example.com",
+		"CORS_ALLOWED_ORIGINS entry 'https://example.com/path' must be '*' or an origin like https://example.com or https://*.example.com",
+	}
+	if !reflect.DeepEqual(expected, cfgErr.Problems) {
+		t.Errorf("Expected problems to be\n%s\nbut was\n%s", strings.Join(expected, "\n"), strings.Join(cfgErr.Problems, "\n"))
+	}
+
+	if !strings.HasPrefix(err.Error(), "invalid configuration:\n  - CORS_ALLOW_CREDENTIALS") {
+		t.Errorf("Expected every problem to be listed, but was '%s'", err.Error())
+	}
+}
+
+func TestLoadAuth(t *testing.T) {
+	env := map[string]string{
+		"JWKS_URL":     "https://example.com/.well-known/jwks.json",
+		"JWT_ISSUER":   "https://example.com/",
+		"JWT_AUDIENCE": "resume-api",
+	}
+
+	cfg, err := loadAuth(lookupFrom(env))
+	if err != nil {
+		t.Fatalf("Expected the authorizer to only need the token settings, but was %s", err.Error())
+	}
+
+	if "https://example.com/" != cfg.Auth.Validator().Issuer {
+		t.Errorf("Expected validator issuer to be 'https://example.com/', but was '%s'", cfg.Auth.Validator().Issuer)
+	}
+
+	env["LOG_LEVEL"] = "loud"
+	env["JWT_ISSUER"] = ""
+	delete(env, "JWT_AUDIENCE")
+	_, err = loadAuth(lookupFrom(env))
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected a configuration error, but was %v", err)
+	}
+
+	expected := []string{
+		"LOG_LEVEL 'loud' must be one of debug, info, warn or error",
+		"JWT_ISSUER is required",
+		"JWT_AUDIENCE is required",
+	}
+	if !reflect.DeepEqual(expected, cfgErr.Problems) {
+		t.Errorf("Expected problems to be\n%s\nbut was\n%s", strings.Join(expected, "\n"), strings.Join(cfgErr.Problems, "\n"))
+	}
+}
+
+func TestValidOrigin(t *testing.T) {
+	tests := map[string]bool{
+		"*":                        true,
+		"https://example.com":      true,
+		"http://localhost:3000":    true,
+		"https://*.example.com":    true,
+		"https://example.com/":     false,
+		"ftp://example.com":        false,
+		"https://a.*.example.com":  false,
+		"https://user@example.com": false,
+		"example.com":              false,
+	}
+
+	for origin, expected := range tests {
+		if actual := validOrigin(origin); expected != actual {
+			t.Errorf("Expected '%s' to be valid: %t, but was %t", origin, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ApiKeyHeader = "X-Api-Key"
+
+	ErrorApiKeyIdNotProvided = "keyId not provided"
+	ErrorApiKeyReadOnly      = "API keys may only be used to read"
+	ErrorInsufficientScope   = "API key does not have the required scope"
+	ErrorInvalidApiKey       = "API key is invalid or has been revoked"
+	ErrorInvalidApiKeyScope  = "unknown API key scope"
+	ErrorMultipleCredentials = "send either a bearer token or an API key, not both"
+)
+
+var (
+	ErrInsufficientScope = errors.New(ErrorInsufficientScope)
+	ErrInvalidApiKey     = errors.New(ErrorInvalidApiKey)
+)
+
+type CreateApiKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateApiKey issues a new key for the user. The full key is only ever returned in this response.
+func CreateApiKey(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	keyReq := &CreateApiKeyRequest{}
+	if len(req.Body) > 0 {
+		if err := decodeJSON(req, keyReq); err != nil {
+			logger.Error("Failed to unmarshal body into CreateApiKeyRequest object", zap.Error(err), zap.String("body", req.Body))
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+	}
+
+	if len(keyReq.Scopes) == 0 {
+		keyReq.Scopes = []string{auth.ScopeReadResume}
+	}
+
+	for _, scope := range keyReq.Scopes {
+		if !auth.IsApiKeyScope(scope) {
+			return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorInvalidApiKeyScope), Field: aws.String("scopes")}, logger)
+		}
+	}
+
+	keyId, key, secretHash, err := auth.NewApiKey("")
+	if err != nil {
+		logger.Error("Failed to generate API key", zap.Error(err))
+		return apiResponse(req, http.StatusInternalServerError, ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+	}
+
+	apiKey := &models.ApiKey{
+		TenantId:   tenantFromRequest(req),
+		KeyId:      keyId,
+		UserId:     userId,
+		Name:       keyReq.Name,
+		Scopes:     keyReq.Scopes,
+		SecretHash: secretHash,
+		CreatedAt:  time.Now().Unix(),
+	}
+	if err := models.PutApiKey(ctx, apiKey, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusCreated, SuccessBody{ApiKey: apiKey, Key: aws.String(key)}, logger)
+}
+
+// RotateApiKey replaces the key's secret, keeping its ID and scopes, so the old key stops working immediately
+func RotateApiKey(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId, keyId, resp, err := apiKeyPathParameters(ctx, req, svc, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	_, key, secretHash, err := auth.NewApiKey(keyId)
+	if err != nil {
+		logger.Error("Failed to generate API key", zap.Error(err))
+		return apiResponse(req, http.StatusInternalServerError, ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+	}
+
+	apiKey, err := models.RotateApiKey(ctx, &models.ApiKeyKey{TenantId: tenantFromRequest(req), KeyId: keyId}, userId, secretHash, time.Now(), svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{ApiKey: apiKey, Key: aws.String(key)}, logger)
+}
+
+func RevokeApiKey(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId, keyId, resp, err := apiKeyPathParameters(ctx, req, svc, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if err := models.RevokeApiKey(ctx, &models.ApiKeyKey{TenantId: tenantFromRequest(req), KeyId: keyId}, userId, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
+}
+
+// apiKeyPathParameters reads and authorizes the path of a single key, returning a response when the request should go
+// no further
+func apiKeyPathParameters(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (string, string, *events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+		return "", "", resp, err
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		resp, err := apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		return "", "", resp, err
+	}
+
+	keyId := req.PathParameters["keyId"]
+	if len(keyId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorApiKeyIdNotProvided)}, logger)
+		return "", "", resp, err
+	}
+
+	return userId, keyId, nil, nil
+}
+
+// authenticateApiKey looks the key up by its ID and checks the secret against the stored hash. Every failure is
+// reported the same way so callers can't probe for which key IDs exist.
+func authenticateApiKey(ctx context.Context, key string, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*auth.Principal, error) {
+	keyId, secret, err := auth.ParseApiKey(key)
+	if err != nil {
+		return nil, ErrInvalidApiKey
+	}
+
+	apiKey, err := models.GetApiKey(ctx, &models.ApiKeyKey{KeyId: keyId}, svc, logger)
+	if errors.Is(err, models.ErrNoResultsFound) {
+		return nil, ErrInvalidApiKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	if apiKey.Revoked || !auth.VerifyApiKeySecret(secret, apiKey.SecretHash) {
+		logger.Warn("Rejected API key", zap.String("key_id", keyId), zap.Bool("revoked", apiKey.Revoked))
+		return nil, ErrInvalidApiKey
+	}
+
+	return &auth.Principal{
+		Subject:  "apikey|" + apiKey.KeyId,
+		TenantId: apiKey.TenantId,
+		Scopes:   apiKey.Scopes,
+		Method:   auth.MethodApiKey,
+	}, nil
+}
+
+// requireScope rejects API key callers without the scope. Users signed in with a token are not limited by scopes.
+func requireScope(req events.APIGatewayProxyRequest, scope string) error {
+	if principal, ok := PrincipalFromRequest(req); ok && principal.Method == auth.MethodApiKey && !principal.HasScope(scope) {
+		return ErrInsufficientScope
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestApiKeyLifecycle(t *testing.T) {
+	setupHandler(t)
+
+	var stored *models.ApiKey
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		stored = &models.ApiKey{}
+		_ = dynamodbattribute.UnmarshalMap(input.Item, stored)
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	userAttr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		if models.ApiKeysTable == *input.TableName {
+			attr, _ := dynamodbattribute.MarshalMap(stored)
+			return &dynamodb.GetItemOutput{Item: attr}, nil
+		}
+		return &dynamodb.GetItemOutput{Item: userAttr}, nil
+	}
+
+	createEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/api-keys",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           `{"name": "job board", "scopes": ["read:search"]}`,
+	}
+	created := &SuccessBody{}
+	if res, err := CreateApiKey(context.Background(), createEvent, svc, logger); err != nil {
+		t.Fatalf("Failed to get a response for CreateApiKey: %s", err.Error())
+	} else if http.StatusCreated != res.StatusCode {
+		t.Fatalf("Expected status code to be %d, but was %d: %s", http.StatusCreated, res.StatusCode, res.Body)
+	} else if strings.Contains(res.Body, stored.SecretHash) {
+		t.Errorf("Expected the secret hash to be left out of the response")
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), created); jsonErr != nil {
+		t.Fatalf("Failed to unmarshal response body: %s", jsonErr.Error())
+	}
+
+	if created.Key == nil || created.ApiKey == nil {
+		t.Fatalf("Expected a key and its details in the response")
+	}
+
+	if strings.Contains(stored.SecretHash, strings.Split(*created.Key, "_")[2]) {
+		t.Errorf("Expected only a hash of the secret to be stored")
+	}
+
+	router := NewRouter()
+	router.Use(Authenticate(nil, false))
+	router.Handle("GET", "/user/{id}", GetUser)
+	router.Handle("DELETE", "/user/{id}", DeleteUser)
+	getEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "GET",
+		Headers:        map[string]string{"x-api-key": *created.Key},
+		PathParameters: map[string]string{"id": "user1"},
+	}
+
+	// The key was only issued for search, so it can't read resumes yet
+	if res, err := router.Route(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	stored.Scopes = append(stored.Scopes, auth.ScopeReadResume)
+	body := &SuccessBody{}
+	if res, err := router.Route(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if len(body.User.Email) > 0 {
+		t.Errorf("Expected an API key to only see the public resume")
+	}
+
+	deleteEvent := getEvent
+	deleteEvent.HTTPMethod = "DELETE"
+	if res, err := router.Route(context.Background(), deleteEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for DeleteUser: %s", err.Error())
+	} else if http.StatusUnauthorized != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	rotateEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/api-keys/{keyId}/rotate",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1", "keyId": stored.KeyId},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		rotated := *stored
+		rotated.SecretHash = *input.ExpressionAttributeValues[":hash"].S
+		stored = &rotated
+		attr, _ := dynamodbattribute.MarshalMap(stored)
+		return &dynamodb.UpdateItemOutput{Attributes: attr}, nil
+	}
+	rotated := &SuccessBody{}
+	if res, err := RotateApiKey(context.Background(), rotateEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for RotateApiKey: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), rotated); jsonErr != nil || rotated.Key == nil {
+		t.Errorf("Expected a new key in the response")
+	} else if !strings.Contains(*rotated.Key, stored.KeyId) {
+		t.Errorf("Expected the rotated key to keep its ID")
+	}
+
+	if res, err := router.Route(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
+	} else if http.StatusUnauthorized != res.StatusCode {
+		t.Errorf("Expected the old key to be rejected after rotation, but status code was %d", res.StatusCode)
+	}
+
+	stored.Revoked = true
+	getEvent.Headers["x-api-key"] = *rotated.Key
+	if res, err := router.Route(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
+	} else if http.StatusUnauthorized != res.StatusCode {
+		t.Errorf("Expected a revoked key to be rejected, but status code was %d", res.StatusCode)
+	}
+}
+
+func TestCreateApiKeyValidation(t *testing.T) {
+	setupHandler(t)
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/api-keys",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           `{"scopes": ["write:resume"]}`,
+	}
+	if res, err := CreateApiKey(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for CreateApiKey: %s", err.Error())
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	event.RequestContext.Authorizer = authorizerFor("user2")
+	if res, err := CreateApiKey(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for CreateApiKey: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+}
@@ -1,42 +1,232 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/bkimbrough88/resume-backend/pkg/linkedin"
 	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/share"
+	"github.com/bkimbrough88/resume-backend/pkg/storage"
+	"github.com/bkimbrough88/resume-backend/pkg/tailor"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"go.uber.org/zap"
 )
 
-func apiResponse(status int, body interface{}, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+func apiResponse(req events.APIGatewayProxyRequest, status int, body interface{}, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	encoder, ok := negotiateEncoder(getHeader(req, "Accept"))
+	if !ok {
+		logger.Warn("No encoder matches the accept header", zap.String("accept", getHeader(req, "Accept")))
+		encoder = encoders[0]
+		status = http.StatusNotAcceptable
+		body = ErrorBody{ErrorMsg: aws.String(ErrorNotAcceptable)}
+	}
+
+	mediaType := encoder.MediaType
+	if errBody, ok := body.(ErrorBody); ok {
+		body = problem(req, status, errBody)
+		if problemType, ok := problemMediaTypes[mediaType]; ok {
+			mediaType = problemType
+		}
+	}
+
 	resp := events.APIGatewayProxyResponse{
 		Headers: map[string]string{
-			"Access-Control-Allow-Origin": "*",
-			"Access-Control-Allow-Headers": "Authorization",
-			"Access-Control-Allow-Methods": "GET, POST, OPTIONS, DELETE",
-			"Content-Type": "application/json",
+			"Content-Type": mediaType,
+			"Vary":         "Accept",
 		},
 	}
+	if len(req.RequestContext.RequestID) > 0 {
+		resp.Headers[RequestIdHeader] = req.RequestContext.RequestID
+	}
+	if errBody, ok := body.(ErrorBody); ok && errBody.RetryAfter != nil {
+		resp.Headers["Retry-After"] = strconv.Itoa(*errBody.RetryAfter)
+	}
 	resp.StatusCode = status
 
-	stringBody, err := json.Marshal(body)
+	encodedBody, err := encoder.Encode(status, body)
 	if err != nil {
-		logger.Error("Failed to marshal body", zap.Error(err), zap.Any("body", body))
+		logger.Error("Failed to encode body", zap.Error(err), zap.String("media_type", encoder.MediaType), zap.Any("body", body))
 	}
 
-	resp.Body = string(stringBody)
+	if encoder.Binary {
+		resp.Body = base64.StdEncoding.EncodeToString(encodedBody)
+		resp.IsBase64Encoded = true
+	} else {
+		resp.Body = string(encodedBody)
+	}
 	return &resp, nil
 }
 
-func getErrorStatusCode(err error) int {
-	if err.Error() == models.ErrorInvalidEmail || err.Error() == models.ErrorInvalidUserId {
-		return http.StatusBadRequest
+// ProblemTypeBase prefixes the code of a problem to make its type URI. Problems that only have a generic status code
+// use about:blank, as RFC 7807 suggests.
+var ProblemTypeBase = "urn:resume-backend:problem:"
+
+// problem fills in the members of a problem document that come from the response rather than the failure
+func problem(req events.APIGatewayProxyRequest, status int, body ErrorBody) ErrorBody {
+	generic := statusCode(status)
+	if body.Code == nil {
+		body.Code = aws.String(generic)
+	}
+	if body.Type == nil {
+		if *body.Code == generic {
+			body.Type = aws.String("about:blank")
+		} else {
+			body.Type = aws.String(ProblemTypeBase + *body.Code)
+		}
+	}
+	if body.Title == nil {
+		body.Title = aws.String(http.StatusText(status))
+	}
+	body.Status = aws.Int(status)
+	if body.Instance == nil && len(req.Path) > 0 {
+		body.Instance = aws.String(req.Path)
+	}
+	if body.RequestId == nil && len(req.RequestContext.RequestID) > 0 {
+		body.RequestId = aws.String(req.RequestContext.RequestID)
+	}
+
+	return body
+}
+
+// errorMapping ties an error to the status and stable code it is reported with
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings are matched in order with errors.Is, so wrapped errors still map
+var errorMappings = []errorMapping{
+	{models.ErrInvalidEmail, http.StatusBadRequest, "invalid_email"},
+	{models.ErrInvalidUserId, http.StatusBadRequest, "invalid_user_id"},
+	{models.ErrInvalidVisibility, http.StatusBadRequest, "invalid_visibility"},
+	{models.ErrUnknownVisibilityField, http.StatusBadRequest, "unknown_visibility_field"},
+	{models.ErrInvalidOrgId, http.StatusBadRequest, "invalid_org_id"},
+	{models.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{models.ErrInvalidTenantId, http.StatusBadRequest, "invalid_tenant_id"},
+	{models.ErrInvalidResumeId, http.StatusBadRequest, "invalid_resume_id"},
+	{models.ErrUnknownResumeEntry, http.StatusBadRequest, "unknown_resume_entry"},
+	{models.ErrEndBeforeStart, http.StatusBadRequest, "end_before_start"},
+	{models.ErrInvalidDate, http.StatusBadRequest, "invalid_date"},
+	{tailor.ErrEmptyJobDescription, http.StatusBadRequest, "empty_job_description"},
+	{models.ErrInvalidIdempotencyKey, http.StatusBadRequest, "invalid_idempotency_key"},
+	{models.ErrUnknownField, http.StatusBadRequest, "unknown_field"},
+	{ErrInvalidType, http.StatusBadRequest, "invalid_type"},
+	{ErrMalformedBody, http.StatusBadRequest, "malformed_body"},
+	{ErrBodyTooDeep, http.StatusBadRequest, "body_too_deep"},
+	{ErrBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large"},
+	{models.ErrItemTooLarge, http.StatusRequestEntityTooLarge, "item_too_large"},
+	{linkedin.ErrArchiveTooLarge, http.StatusRequestEntityTooLarge, "archive_too_large"},
+	{models.ErrOrgAlreadyExists, http.StatusConflict, "org_already_exists"},
+	{models.ErrLastOwner, http.StatusConflict, "last_owner"},
+	{models.ErrMembershipChanged, http.StatusConflict, "membership_changed"},
+	{ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency_key_in_progress"},
+	{ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{ErrAuthenticationRequired, http.StatusUnauthorized, "authentication_required"},
+	{ErrInvalidApiKey, http.StatusUnauthorized, "invalid_api_key"},
+	{ErrForbidden, http.StatusForbidden, "forbidden"},
+	{ErrInsufficientScope, http.StatusForbidden, "insufficient_scope"},
+	{ErrOrgForbidden, http.StatusForbidden, "org_forbidden"},
+	{models.ErrNoResultsFound, http.StatusNotFound, "not_found"},
+	{share.ErrInvalidToken, http.StatusNotFound, "invalid_share_token"},
+	{models.ErrShareUnavailable, http.StatusGone, "share_unavailable"},
+	{share.ErrTokenExpired, http.StatusGone, "share_token_expired"},
+	{storage.ErrCircuitOpen, http.StatusServiceUnavailable, "storage_unavailable"},
+}
+
+// awsErrorMappings covers DynamoDB failures that aren't the server's fault, keyed by awserr code. A failed condition
+// that the models didn't already translate means the item changed underneath the request, and a cancelled request
+// ran out of time.
+var awsErrorMappings = map[string]errorMapping{
+	dynamodb.ErrCodeConditionalCheckFailedException:        {status: http.StatusConflict, code: "conflict"},
+	dynamodb.ErrCodeTransactionConflictException:           {status: http.StatusConflict, code: "conflict"},
+	dynamodb.ErrCodeProvisionedThroughputExceededException: {status: http.StatusTooManyRequests, code: "throttled"},
+	dynamodb.ErrCodeRequestLimitExceeded:                   {status: http.StatusTooManyRequests, code: "throttled"},
+	"ThrottlingException":                                  {status: http.StatusTooManyRequests, code: "throttled"},
+	dynamodb.ErrCodeInternalServerError:                    {status: http.StatusServiceUnavailable, code: "service_unavailable"},
+	request.CanceledErrorCode:                              {status: http.StatusGatewayTimeout, code: "timeout"},
+}
+
+// awsRetryAfter is how long to tell clients to wait before retrying DynamoDB failures that are reported as a 503
+var awsRetryAfter = map[string]time.Duration{
+	dynamodb.ErrCodeInternalServerError: time.Second,
+}
+
+// classifyError picks the status and code to report an error with, falling back to a 500
+func classifyError(err error) (int, string) {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusUnprocessableEntity, "validation_failed"
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return http.StatusGatewayTimeout, "timeout"
 	}
 
-	if err.Error() == models.ErrorNoResultsFound {
-		return http.StatusNotFound
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.code
+		}
 	}
 
-	return http.StatusInternalServerError
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		if mapping, ok := awsErrorMappings[aerr.Code()]; ok {
+			return mapping.status, mapping.code
+		}
+	}
+
+	return http.StatusInternalServerError, "internal_error"
+}
+
+// statusCode is the code for error bodies built without an error to classify, e.g. "bad_request"
+func statusCode(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+func newErrorBody(err error) ErrorBody {
+	_, code := classifyError(err)
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		body := ErrorBody{ErrorMsg: aws.String(ErrorValidationFailed), Code: aws.String(code)}
+		for _, violation := range validationErr.Violations {
+			body.Violations = append(body.Violations, Violation{Field: violation.Field, Code: violation.Code, Message: violation.Error()})
+		}
+		return body
+	}
+
+	body := ErrorBody{ErrorMsg: aws.String(err.Error()), Code: aws.String(code)}
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		body.Field = aws.String(fieldErr.Field)
+		if fieldErr.Line > 0 {
+			body.Line = aws.Int(fieldErr.Line)
+		}
+	}
+
+	var circuitErr *storage.CircuitOpenError
+	var aerr awserr.Error
+	if errors.As(err, &circuitErr) {
+		body.RetryAfter = aws.Int(int(math.Ceil(circuitErr.RetryAfter.Seconds())))
+	} else if errors.As(err, &aerr) && awsRetryAfter[aerr.Code()] > 0 {
+		body.RetryAfter = aws.Int(int(math.Ceil(awsRetryAfter[aerr.Code()].Seconds())))
+	}
+
+	return body
+}
+
+func getErrorStatusCode(err error) int {
+	status, _ := classifyError(err)
+	return status
 }
@@ -3,11 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/storage"
 	"go.uber.org/zap"
 )
 
@@ -30,7 +37,7 @@ func TestApiResponse(t *testing.T) {
 	setupApiResponse()
 
 	successNoUser := SuccessBody{}
-	if res, err := apiResponse(http.StatusOK, successNoUser, logger); err != nil {
+	if res, err := apiResponse(events.APIGatewayProxyRequest{}, http.StatusOK, successNoUser, logger); err != nil {
 		t.Errorf("Failed to get API response: %s", err.Error())
 	} else {
 		if http.StatusOK != res.StatusCode {
@@ -47,7 +54,7 @@ func TestApiResponse(t *testing.T) {
 	}
 
 	successWithUser := SuccessBody{User: user}
-	if res, err := apiResponse(http.StatusOK, successWithUser, logger); err != nil {
+	if res, err := apiResponse(events.APIGatewayProxyRequest{}, http.StatusOK, successWithUser, logger); err != nil {
 		t.Errorf("Failed to get API response: %s", err.Error())
 	} else {
 		if http.StatusOK != res.StatusCode {
@@ -75,15 +82,15 @@ func TestApiResponse(t *testing.T) {
 	}
 
 	errorBody := ErrorBody{ErrorMsg: aws.String("bad request")}
-	if res, err := apiResponse(http.StatusBadRequest, errorBody, logger); err != nil {
+	if res, err := apiResponse(events.APIGatewayProxyRequest{}, http.StatusBadRequest, errorBody, logger); err != nil {
 		t.Errorf("Failed to get API response: %s", err.Error())
 	} else {
 		if http.StatusBadRequest != res.StatusCode {
 			t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
 		}
 
-		if contentTypeApplicationJson != res.Headers[contentType] {
-			t.Errorf("Expected %s header to be '%s', but was '%s'", contentType, contentTypeApplicationJson, res.Headers[contentType])
+		if MediaTypeProblemJSON != res.Headers[contentType] {
+			t.Errorf("Expected %s header to be '%s', but was '%s'", contentType, MediaTypeProblemJSON, res.Headers[contentType])
 		}
 
 		resErrorBody := &ErrorBody{}
@@ -102,19 +109,152 @@ func TestApiResponse(t *testing.T) {
 func TestGetErrorStatusCode(t *testing.T) {
 	setupApiResponse()
 
-	if code := getErrorStatusCode(errors.New(models.ErrorInvalidEmail)); http.StatusBadRequest != code {
+	if code := getErrorStatusCode(models.ErrInvalidEmail); http.StatusBadRequest != code {
 		t.Errorf("Expected status code for error '%s' to be %d, but was %d", models.ErrorInvalidEmail, http.StatusBadRequest, code)
 	}
 
-	if code := getErrorStatusCode(errors.New(models.ErrorInvalidUserId)); http.StatusBadRequest != code {
+	if code := getErrorStatusCode(models.ErrInvalidUserId); http.StatusBadRequest != code {
 		t.Errorf("Expected status code for error '%s' to be %d, but was %d", models.ErrorInvalidUserId, http.StatusBadRequest, code)
 	}
 
-	if code := getErrorStatusCode(errors.New(models.ErrorNoResultsFound)); http.StatusNotFound != code {
+	if code := getErrorStatusCode(models.ErrEndBeforeStart); http.StatusBadRequest != code {
+		t.Errorf("Expected status code for error '%s' to be %d, but was %d", models.ErrorEndBeforeStart, http.StatusBadRequest, code)
+	}
+
+	if code := getErrorStatusCode(models.ErrNoResultsFound); http.StatusNotFound != code {
 		t.Errorf("Expected status code for error '%s' to be %d, but was %d", models.ErrorNoResultsFound, http.StatusNotFound, code)
 	}
 
+	if code := getErrorStatusCode(fmt.Errorf("loading user: %w", models.ErrNoResultsFound)); http.StatusNotFound != code {
+		t.Errorf("Expected status code for wrapped error '%s' to be %d, but was %d", models.ErrorNoResultsFound, http.StatusNotFound, code)
+	}
+
+	if code := getErrorStatusCode(errors.New(models.ErrorNoResultsFound)); http.StatusInternalServerError != code {
+		t.Errorf("Expected status code for an unrelated error with the same message to be %d, but was %d", http.StatusInternalServerError, code)
+	}
+
+	if code := getErrorStatusCode(awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)); http.StatusConflict != code {
+		t.Errorf("Expected status code for a failed condition to be %d, but was %d", http.StatusConflict, code)
+	}
+
+	if code := getErrorStatusCode(awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)); http.StatusTooManyRequests != code {
+		t.Errorf("Expected status code for exceeded throughput to be %d, but was %d", http.StatusTooManyRequests, code)
+	}
+
+	if code := getErrorStatusCode(awserr.New(dynamodb.ErrCodeInternalServerError, "internal error", nil)); http.StatusServiceUnavailable != code {
+		t.Errorf("Expected status code for a DynamoDB internal error to be %d, but was %d", http.StatusServiceUnavailable, code)
+	}
+
 	if code := getErrorStatusCode(errors.New("some other error")); http.StatusInternalServerError != code {
 		t.Errorf("Expected status code for error 'some other error' to be %d, but was %d", http.StatusInternalServerError, code)
 	}
 }
+
+func TestNewErrorBodyCode(t *testing.T) {
+	body := newErrorBody(fmt.Errorf("checking key: %w", ErrInvalidApiKey))
+	if body.Code == nil || *body.Code != "invalid_api_key" {
+		t.Errorf("Expected code to be 'invalid_api_key', but was %v", body.Code)
+	}
+	if *body.ErrorMsg != "checking key: "+ErrorInvalidApiKey {
+		t.Errorf("Expected the message to be kept, but was '%s'", *body.ErrorMsg)
+	}
+
+	body = newErrorBody(awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "limit exceeded", nil))
+	if body.Code == nil || *body.Code != "throttled" {
+		t.Errorf("Expected code to be 'throttled', but was %v", body.Code)
+	}
+}
+
+func TestApiResponseDefaultsErrorCode(t *testing.T) {
+	logger := zap.NewNop()
+	resp, _ := apiResponse(events.APIGatewayProxyRequest{}, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	if !strings.Contains(resp.Body, `"code":"bad_request"`) {
+		t.Errorf("Expected body to carry the generic code, but was %s", resp.Body)
+	}
+}
+
+func TestApiResponseProblem(t *testing.T) {
+	logger := zap.NewNop()
+	req := events.APIGatewayProxyRequest{
+		Path:           "/user/user1",
+		RequestContext: events.APIGatewayProxyRequestContext{RequestID: "request-1"},
+	}
+
+	res, _ := apiResponse(req, http.StatusConflict, newErrorBody(models.ErrOrgAlreadyExists), logger)
+	problem := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(res.Body), &problem); err != nil {
+		t.Fatalf("Failed to unmarshal response body: %s", err.Error())
+	}
+
+	expected := map[string]interface{}{
+		"type":       ProblemTypeBase + "org_already_exists",
+		"title":      http.StatusText(http.StatusConflict),
+		"status":     float64(http.StatusConflict),
+		"detail":     models.ErrorOrgAlreadyExists,
+		"instance":   "/user/user1",
+		"request_id": "request-1",
+		"code":       "org_already_exists",
+	}
+	for member, value := range expected {
+		if problem[member] != value {
+			t.Errorf("Expected %s to be '%v', but was '%v'", member, value, problem[member])
+		}
+	}
+
+	res, _ = apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	if !strings.Contains(res.Body, `"type":"about:blank"`) {
+		t.Errorf("Expected a problem with only a generic code to have type about:blank, but was %s", res.Body)
+	}
+}
+
+func TestApiResponseProblemErrors(t *testing.T) {
+	logger := zap.NewNop()
+	err := &models.ValidationError{Violations: []*models.FieldError{
+		{Field: "email", Code: models.CodeInvalidFormat, Err: models.ErrInvalidEmail},
+	}}
+
+	res, _ := apiResponse(events.APIGatewayProxyRequest{}, http.StatusUnprocessableEntity, newErrorBody(err), logger)
+	body := &ErrorBody{}
+	if unmarshalErr := json.Unmarshal([]byte(res.Body), body); unmarshalErr != nil {
+		t.Fatalf("Failed to unmarshal response body: %s", unmarshalErr.Error())
+	}
+
+	if len(body.Violations) != 1 || body.Violations[0].Field != "email" || body.Violations[0].Code != models.CodeInvalidFormat {
+		t.Errorf("Expected errors to hold the email violation, but was %s", res.Body)
+	}
+}
+
+func TestApiResponseRetryAfter(t *testing.T) {
+	err := fmt.Errorf("getting user: %w", &storage.CircuitOpenError{RetryAfter: 1500 * time.Millisecond})
+	if code := getErrorStatusCode(err); http.StatusServiceUnavailable != code {
+		t.Errorf("Expected status code for an open circuit to be %d, but was %d", http.StatusServiceUnavailable, code)
+	}
+
+	res, _ := apiResponse(events.APIGatewayProxyRequest{}, getErrorStatusCode(err), newErrorBody(err), zap.NewNop())
+	if res.Headers["Retry-After"] != "2" {
+		t.Errorf("Expected Retry-After header to be '2', but was '%s'", res.Headers["Retry-After"])
+	}
+	if strings.Contains(res.Body, "retry") {
+		t.Errorf("Expected the retry delay to only be sent as a header, but the body was %s", res.Body)
+	}
+	// DynamoDB failing on its side is reported the same way
+	err = awserr.New(dynamodb.ErrCodeInternalServerError, "internal error", nil)
+	res, _ = apiResponse(events.APIGatewayProxyRequest{}, getErrorStatusCode(err), newErrorBody(err), zap.NewNop())
+	if http.StatusServiceUnavailable != res.StatusCode || res.Headers["Retry-After"] != "1" {
+		t.Errorf("Expected a 503 with Retry-After '1', but was %d with '%s'", res.StatusCode, res.Headers["Retry-After"])
+	}
+}
+
+func TestApiResponseProblemXML(t *testing.T) {
+	logger := zap.NewNop()
+	req := events.APIGatewayProxyRequest{Headers: map[string]string{"Accept": MediaTypeProblemXML}}
+
+	res, _ := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String("bad request")}, logger)
+	if MediaTypeProblemXML != res.Headers[contentType] {
+		t.Errorf("Expected %s header to be '%s', but was '%s'", contentType, MediaTypeProblemXML, res.Headers[contentType])
+	}
+
+	if !strings.Contains(res.Body, `<problem xmlns="urn:ietf:rfc:7807">`) {
+		t.Errorf("Expected an RFC 7807 problem element, but was %s", res.Body)
+	}
+}
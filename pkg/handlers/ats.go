@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/ats"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+// GetAtsReport checks the user's resume for problems that get it filtered out by applicant tracking systems. The
+// report points at fields the public can't see, so only those who can edit the resume may request it.
+func GetAtsReport(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: tenantFromRequest(req), UserId: userId}, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{AtsReport: ats.Check(user)}, logger)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/ats"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestGetAtsReport(t *testing.T) {
+	setupHandler(t)
+	user.Experience = []models.Experience{{Company: "Co", JobTitle: "SRE", Responsibilities: []string{"Responsible for uptime"}}}
+
+	attr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: attr}, nil
+	}
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/ats-report",
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+	body := &SuccessBody{}
+	if res, err := GetAtsReport(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetAtsReport: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if body.AtsReport == nil || ats.MaxScore <= body.AtsReport.Score || len(body.AtsReport.Findings) != 2 {
+		t.Errorf("Expected the missing start date and weak responsibility to be reported, but was %+v", body.AtsReport)
+	}
+
+	event.RequestContext.Authorizer = authorizerFor("user2")
+	if res, err := GetAtsReport(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetAtsReport: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+}
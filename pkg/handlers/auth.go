@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorAuthenticationRequired = "authentication required"
+	ErrorAuthNotConfigured      = "authentication is not configured"
+	ErrorForbidden              = "not allowed to modify another user's resume"
+	ErrorOrgForbidden           = "not allowed to act on this organization"
+	ErrorMalformedAuthorization = "authorization header must be a bearer token"
+)
+
+var (
+	ErrAuthenticationRequired = errors.New(ErrorAuthenticationRequired)
+	ErrForbidden              = errors.New(ErrorForbidden)
+	ErrOrgForbidden           = errors.New(ErrorOrgForbidden)
+)
+
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Authenticate validates the bearer token or API key when one is sent and stores the principal in the request's
+// authorizer context. Reads may be anonymous, but any write without a valid token is rejected, and API keys are only
+// accepted for reads. A nil validator rejects every token, so a misconfigured function fails closed.
+//
+// When trustAuthorizer is set, a principal already put in the context by the Lambda authorizer in front of this
+// function is used as is rather than validating the token again. Only set it when the function can only be invoked
+// through API Gateway, since anything invoking it directly could set the context itself.
+func Authenticate(validator *auth.Validator, trustAuthorizer bool) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+			if trustAuthorizer {
+				if principal, ok := PrincipalFromRequest(req); ok {
+					return next(ctx, req, svc, logger.With(zap.String("principal", principal.Subject)))
+				}
+			}
+
+			authorizer := make(map[string]interface{})
+			for key, value := range req.RequestContext.Authorizer {
+				authorizer[key] = value
+			}
+			for _, key := range auth.ContextKeys {
+				delete(authorizer, key)
+			}
+
+			header := getHeader(req, "Authorization")
+			apiKey := getHeader(req, ApiKeyHeader)
+			if len(header) > 0 && len(apiKey) > 0 {
+				return unauthorized(req, ErrorMultipleCredentials, logger)
+			}
+
+			if len(apiKey) > 0 {
+				if req.HTTPMethod != http.MethodGet && req.HTTPMethod != http.MethodHead {
+					return unauthorized(req, ErrorApiKeyReadOnly, logger)
+				}
+
+				principal, err := authenticateApiKey(ctx, apiKey, svc, logger)
+				if errors.Is(err, ErrInvalidApiKey) {
+					return unauthorized(req, err.Error(), logger)
+				} else if err != nil {
+					return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+				}
+
+				for key, value := range principal.AuthorizerContext() {
+					authorizer[key] = value
+				}
+				logger = logger.With(zap.String("principal", principal.Subject))
+			} else if len(header) > 0 {
+				parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+				if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || len(strings.TrimSpace(parts[1])) == 0 {
+					return unauthorized(req, ErrorMalformedAuthorization, logger)
+				}
+				token := strings.TrimSpace(parts[1])
+
+				if validator == nil {
+					logger.Error("Received a bearer token, but no token validator is configured")
+					return unauthorized(req, ErrorAuthNotConfigured, logger)
+				}
+
+				principal, err := validator.Validate(token)
+				if err != nil {
+					logger.Warn("Rejected bearer token", zap.Error(err))
+					return unauthorized(req, err.Error(), logger)
+				}
+
+				for key, value := range principal.AuthorizerContext() {
+					authorizer[key] = value
+				}
+				logger = logger.With(zap.String("principal", principal.Subject))
+			} else if isWriteMethod(req.HTTPMethod) {
+				return unauthorized(req, ErrorAuthenticationRequired, logger)
+			}
+
+			req.RequestContext.Authorizer = authorizer
+			return next(ctx, req, svc, logger)
+		}
+	}
+}
+
+func PrincipalFromRequest(req events.APIGatewayProxyRequest) (*auth.Principal, bool) {
+	return auth.PrincipalFromAuthorizer(req.RequestContext.Authorizer)
+}
+
+// tenantFromRequest is the tenant every read and write for the request is confined to. It only ever comes from the
+// authenticated principal, anonymous callers and principals without a tenant use the default one.
+func tenantFromRequest(req events.APIGatewayProxyRequest) string {
+	principal, _ := PrincipalFromRequest(req)
+	return tenantOf(principal)
+}
+
+func tenantOf(principal *auth.Principal) string {
+	if principal == nil || len(principal.TenantId) == 0 {
+		return models.DefaultTenant
+	}
+
+	return principal.TenantId
+}
+
+// accessLevel decides how much of the user's resume the caller may see. Members of the organization that owns the
+// resume see all of it, whatever their role.
+func accessLevel(ctx context.Context, req events.APIGatewayProxyRequest, user *models.User, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) models.AccessLevel {
+	principal, ok := PrincipalFromRequest(req)
+	if !ok {
+		return models.AccessPublic
+	}
+
+	if principal.UserId == user.UserId || len(orgRole(ctx, principal, user.OrgId, svc, logger)) > 0 {
+		return models.AccessOwner
+	}
+
+	return models.AccessPublic
+}
+
+// authorize checks the caller may modify userId's resume, which they can if it is their own or they are an owner or
+// editor in the organization that owns it. Admins may modify any resume, and every time they do it is logged so the
+// override can be audited.
+func authorize(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, userId string, logger *zap.Logger) error {
+	principal, ok := PrincipalFromRequest(req)
+	if !ok {
+		return ErrAuthenticationRequired
+	}
+
+	if principal.UserId == userId || isAdmin(req, principal, "user_id", userId, logger) {
+		return nil
+	}
+
+	if user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: tenantOf(principal), UserId: userId}, svc, logger); err == nil && orgRole(ctx, principal, user.OrgId, svc, logger).CanEdit() {
+		return nil
+	}
+
+	logger.Warn("Forbidden attempt to modify another user's resume", zap.String("principal", principal.Subject), zap.String("user_id", userId))
+	return ErrForbidden
+}
+
+// authorizeOrg checks the caller may act on the organization with the given role check
+func authorizeOrg(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, orgId string, allowed func(models.Role) bool, logger *zap.Logger) error {
+	principal, ok := PrincipalFromRequest(req)
+	if !ok {
+		return ErrAuthenticationRequired
+	}
+
+	if role := orgRole(ctx, principal, orgId, svc, logger); len(role) > 0 && allowed(role) {
+		return nil
+	}
+
+	if isAdmin(req, principal, "org_id", orgId, logger) {
+		return nil
+	}
+
+	logger.Warn("Forbidden attempt to act on organization", zap.String("principal", principal.Subject), zap.String("org_id", orgId))
+	return ErrOrgForbidden
+}
+
+// orgRole is the caller's role in the organization, which is empty when they aren't a member
+func orgRole(ctx context.Context, principal *auth.Principal, orgId string, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) models.Role {
+	if len(orgId) == 0 {
+		return ""
+	}
+
+	membership, err := models.GetMembership(ctx, &models.MembershipKey{TenantId: tenantOf(principal), OrgId: orgId, UserId: principal.UserId}, svc, logger)
+	if err != nil {
+		return ""
+	}
+
+	return membership.Role
+}
+
+func isAdmin(req events.APIGatewayProxyRequest, principal *auth.Principal, targetKey string, target string, logger *zap.Logger) bool {
+	if !principal.HasRole(auth.RoleAdmin) {
+		return false
+	}
+
+	logger.Warn("Admin overriding ownership",
+		zap.String("principal", principal.Subject),
+		zap.String(targetKey, target),
+		zap.String("method", req.HTTPMethod),
+		zap.String("path", req.Path))
+	return true
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func unauthorized(req events.APIGatewayProxyRequest, msg string, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	resp, err := apiResponse(req, http.StatusUnauthorized, ErrorBody{ErrorMsg: aws.String(msg)}, logger)
+	if resp != nil {
+		resp.Headers["WWW-Authenticate"] = "Bearer"
+	}
+
+	return resp, err
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+var (
+	DefaultCorsAllowedHeaders = []string{"Authorization", "Content-Type", IdempotencyKeyHeader, ApiKeyHeader, RequestIdHeader}
+	DefaultCorsExposedHeaders = []string{RequestIdHeader, "Retry-After", IdempotentReplayedHeader}
+)
+
+// CorsPolicy decides which browser origins may call the API. An allowed origin is either an exact origin such as
+// "https://example.com", a wildcard subdomain such as "https://*.example.com", or "*" for any origin.
+type CorsPolicy struct {
+	AllowedOrigins   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Cors adds CORS headers to responses for allowed origins and answers preflight requests. The methods allowed by a
+// preflight are the ones the router sends in the Allow header of its OPTIONS response, so they always match the routes
+// registered for the resource. It should be the first middleware, so errors from the others can be read by browsers too.
+//
+// Credentials are never allowed for an origin that only matched "*", since that would let any site make authenticated
+// requests.
+func Cors(policy CorsPolicy) Middleware {
+	if policy.AllowedHeaders == nil {
+		policy.AllowedHeaders = DefaultCorsAllowedHeaders
+	}
+	if policy.ExposedHeaders == nil {
+		policy.ExposedHeaders = DefaultCorsExposedHeaders
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+			resp, err := next(ctx, req, svc, logger)
+			if resp == nil {
+				return resp, err
+			}
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]string)
+			}
+
+			origin := getHeader(req, "Origin")
+			if !policy.anyOrigin() {
+				addVary(resp, "Origin")
+			}
+			if len(origin) == 0 {
+				return resp, err
+			}
+
+			exact, ok := policy.matchOrigin(origin)
+			if !ok {
+				logger.Info("Origin is not allowed by the CORS policy", zap.String("origin", origin))
+				return resp, err
+			}
+
+			requestedMethod := getHeader(req, "Access-Control-Request-Method")
+			preflight := req.HTTPMethod == http.MethodOptions && len(requestedMethod) > 0
+			if preflight && !allowsMethod(resp.Headers["Allow"], requestedMethod) {
+				logger.Info("Method is not allowed by the CORS policy", zap.String("method", requestedMethod))
+				return resp, err
+			}
+
+			if exact {
+				resp.Headers["Access-Control-Allow-Origin"] = origin
+				if policy.AllowCredentials {
+					resp.Headers["Access-Control-Allow-Credentials"] = "true"
+				}
+			} else {
+				resp.Headers["Access-Control-Allow-Origin"] = "*"
+			}
+
+			if preflight {
+				resp.Headers["Access-Control-Allow-Methods"] = resp.Headers["Allow"]
+				resp.Headers["Access-Control-Allow-Headers"] = strings.Join(policy.AllowedHeaders, ", ")
+				if policy.MaxAge > 0 {
+					resp.Headers["Access-Control-Max-Age"] = strconv.Itoa(int(policy.MaxAge.Seconds()))
+				}
+			} else if len(policy.ExposedHeaders) > 0 {
+				resp.Headers["Access-Control-Expose-Headers"] = strings.Join(policy.ExposedHeaders, ", ")
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// matchOrigin reports whether the origin is allowed, and whether it was allowed by name rather than only by "*"
+func (p CorsPolicy) matchOrigin(origin string) (exact bool, ok bool) {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == origin || matchWildcardOrigin(allowed, origin) {
+			return true, true
+		}
+	}
+
+	return false, p.anyOrigin()
+}
+
+func (p CorsPolicy) anyOrigin() bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchWildcardOrigin matches patterns like "https://*.example.com", where the wildcard stands for one or more
+// subdomains. The scheme and port have to match and the bare domain does not.
+func matchWildcardOrigin(pattern string, origin string) bool {
+	star := strings.Index(pattern, "*.")
+	if star == -1 {
+		return false
+	}
+
+	prefix, suffix := pattern[:star], pattern[star+1:]
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, "/:@")
+}
+
+func allowsMethod(allow string, method string) bool {
+	for _, allowed := range strings.Split(allow, ",") {
+		if strings.TrimSpace(allowed) == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func addVary(resp *events.APIGatewayProxyResponse, header string) {
+	if vary := resp.Headers["Vary"]; len(vary) > 0 {
+		resp.Headers["Vary"] = vary + ", " + header
+	} else {
+		resp.Headers["Vary"] = header
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+func setupCors(policy CorsPolicy) *Router {
+	router := NewRouter()
+	router.Use(Cors(policy))
+	ok := func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		return apiResponse(req, http.StatusOK, SuccessBody{}, logger)
+	}
+	router.Handle("GET", "/user/{id}", ok)
+	router.Handle("PATCH", "/user/{id}", ok)
+	return router
+}
+
+func TestCors(t *testing.T) {
+	setupHandler(t)
+	router := setupCors(CorsPolicy{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		AllowCredentials: true,
+	})
+
+	tests := map[string]struct {
+		origin  string
+		allowed string
+	}{
+		"exact origin":      {"https://example.com", "https://example.com"},
+		"different case":    {"https://Example.com", "https://Example.com"},
+		"subdomain":         {"https://app.example.org", "https://app.example.org"},
+		"nested subdomain":  {"https://a.b.example.org", "https://a.b.example.org"},
+		"bare domain":       {"https://example.org", ""},
+		"other scheme":      {"http://app.example.org", ""},
+		"lookalike domain":  {"https://app.notexample.org", ""},
+		"with a port":       {"https://app.example.org:8443", ""},
+		"unlisted origin":   {"https://evil.com", ""},
+		"suffix of allowed": {"https://example.com.evil.com", ""},
+		"no origin":         {"", ""},
+	}
+
+	for name, test := range tests {
+		event := events.APIGatewayProxyRequest{
+			Resource:   "/user/{id}",
+			HTTPMethod: "GET",
+			Headers:    map[string]string{"Origin": test.origin},
+		}
+
+		if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for %s: %s", name, err.Error())
+		} else {
+			if test.allowed != res.Headers["Access-Control-Allow-Origin"] {
+				t.Errorf("Expected allowed origin for %s to be '%s', but was '%s'", name, test.allowed, res.Headers["Access-Control-Allow-Origin"])
+			}
+
+			if len(test.allowed) > 0 && "true" != res.Headers["Access-Control-Allow-Credentials"] {
+				t.Errorf("Expected credentials to be allowed for %s", name)
+			}
+
+			if "Accept, Origin" != res.Headers["Vary"] {
+				t.Errorf("Expected responses for %s to vary by origin, but Vary was '%s'", name, res.Headers["Vary"])
+			}
+		}
+	}
+}
+
+func TestCorsAnyOrigin(t *testing.T) {
+	setupHandler(t)
+	router := setupCors(CorsPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	event := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}",
+		HTTPMethod: "GET",
+		Headers:    map[string]string{"Origin": "https://anywhere.com"},
+	}
+	if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response: %s", err.Error())
+	} else {
+		if "*" != res.Headers["Access-Control-Allow-Origin"] {
+			t.Errorf("Expected any origin to be allowed, but was '%s'", res.Headers["Access-Control-Allow-Origin"])
+		}
+
+		if len(res.Headers["Access-Control-Allow-Credentials"]) > 0 {
+			t.Errorf("Expected credentials not to be allowed for a wildcard origin")
+		}
+
+		if "Accept" != res.Headers["Vary"] {
+			t.Errorf("Expected responses not to vary by origin, but Vary was '%s'", res.Headers["Vary"])
+		}
+
+		if RequestIdHeader+", Retry-After, "+IdempotentReplayedHeader != res.Headers["Access-Control-Expose-Headers"] {
+			t.Errorf("Expected the default headers to be exposed, but was '%s'", res.Headers["Access-Control-Expose-Headers"])
+		}
+	}
+}
+
+func TestCorsPreflight(t *testing.T) {
+	setupHandler(t)
+	router := setupCors(CorsPolicy{AllowedOrigins: []string{"https://example.com"}, MaxAge: time.Hour})
+
+	tests := map[string]struct {
+		origin  string
+		method  string
+		allowed bool
+	}{
+		"allowed":            {"https://example.com", "PATCH", true},
+		"unregistered verb":  {"https://example.com", "DELETE", false},
+		"disallowed origin":  {"https://evil.com", "GET", false},
+		"plain options call": {"https://example.com", "", false},
+	}
+
+	for name, test := range tests {
+		event := events.APIGatewayProxyRequest{
+			Resource:   "/user/{id}",
+			HTTPMethod: "OPTIONS",
+			Headers: map[string]string{
+				"Origin":                         test.origin,
+				"Access-Control-Request-Method":  test.method,
+				"Access-Control-Request-Headers": "authorization",
+			},
+		}
+
+		if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for %s: %s", name, err.Error())
+		} else {
+			if http.StatusNoContent != res.StatusCode {
+				t.Errorf("Expected status code for %s to be %d, but was %d", name, http.StatusNoContent, res.StatusCode)
+			}
+
+			if "GET, OPTIONS, PATCH" != res.Headers["Allow"] {
+				t.Errorf("Expected %s to allow the registered methods, but was '%s'", name, res.Headers["Allow"])
+			}
+
+			if !test.allowed {
+				if len(res.Headers["Access-Control-Allow-Methods"]) > 0 {
+					t.Errorf("Expected %s not to be allowed, but allowed methods were '%s'", name, res.Headers["Access-Control-Allow-Methods"])
+				}
+				continue
+			}
+
+			if "GET, OPTIONS, PATCH" != res.Headers["Access-Control-Allow-Methods"] {
+				t.Errorf("Expected %s to allow the registered methods, but was '%s'", name, res.Headers["Access-Control-Allow-Methods"])
+			}
+
+			if "https://example.com" != res.Headers["Access-Control-Allow-Origin"] {
+				t.Errorf("Expected %s to allow the origin, but was '%s'", name, res.Headers["Access-Control-Allow-Origin"])
+			}
+
+			if "3600" != res.Headers["Access-Control-Max-Age"] {
+				t.Errorf("Expected %s to be cached for an hour, but was '%s'", name, res.Headers["Access-Control-Max-Age"])
+			}
+
+			if len(res.Headers["Access-Control-Allow-Headers"]) == 0 {
+				t.Errorf("Expected %s to allow request headers", name)
+			}
+		}
+	}
+}
+
+func TestCorsUnrouted(t *testing.T) {
+	setupHandler(t)
+	router := setupCors(CorsPolicy{AllowedOrigins: []string{"https://example.com"}})
+
+	tests := map[string]struct {
+		resource string
+		method   string
+		status   int
+	}{
+		"unknown resource":   {"/unknown", "GET", http.StatusNotFound},
+		"unregistered verb":  {"/user/{id}", "DELETE", http.StatusMethodNotAllowed},
+		"unknown preflight":  {"/unknown", "OPTIONS", http.StatusNotFound},
+		"registered request": {"/user/{id}", "GET", http.StatusOK},
+	}
+
+	for name, test := range tests {
+		event := events.APIGatewayProxyRequest{
+			Resource:   test.resource,
+			HTTPMethod: test.method,
+			Headers:    map[string]string{"Origin": "https://example.com"},
+		}
+
+		if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for %s: %s", name, err.Error())
+		} else if test.status != res.StatusCode {
+			t.Errorf("Expected status code for %s to be %d, but was %d", name, test.status, res.StatusCode)
+		} else if "https://example.com" != res.Headers["Access-Control-Allow-Origin"] {
+			t.Errorf("Expected %s to be readable by the origin, but allowed origin was '%s'", name, res.Headers["Access-Control-Allow-Origin"])
+		}
+	}
+}
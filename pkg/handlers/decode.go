@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+const (
+	ErrorBodyTooDeep    = "request body is nested too deeply"
+	ErrorBodyTooLarge   = "request body is too large"
+	ErrorInvalidType    = "field has the wrong type"
+	ErrorMalformedBody  = "request body is not valid JSON"
+	ErrorTrailingData   = "request body has data after the JSON value"
+	unknownFieldMessage = "json: unknown field "
+)
+
+var (
+	ErrBodyTooDeep   = errors.New(ErrorBodyTooDeep)
+	ErrBodyTooLarge  = errors.New(ErrorBodyTooLarge)
+	ErrInvalidType   = errors.New(ErrorInvalidType)
+	ErrMalformedBody = errors.New(ErrorMalformedBody)
+)
+
+// Limits on request bodies, checked before they are decoded. They can be changed at startup.
+var (
+	MaxBodyBytes = 1 << 20
+	MaxBodyDepth = 32
+)
+
+// checkBody rejects bodies over the size limit
+func checkBody(req events.APIGatewayProxyRequest) error {
+	if len(req.Body) > MaxBodyBytes {
+		return fmt.Errorf("%w, the limit is %d bytes", ErrBodyTooLarge, MaxBodyBytes)
+	}
+
+	return nil
+}
+
+// decodeJSON strictly decodes the body into v. Unknown fields are an error rather than being dropped, and errors about a
+// particular field are returned as a FieldError naming it.
+func decodeJSON(req events.APIGatewayProxyRequest, v interface{}) error {
+	if err := checkBody(req); err != nil {
+		return err
+	}
+
+	if depth := jsonDepth(req.Body); depth > MaxBodyDepth {
+		return fmt.Errorf("%w, the limit is %d levels", ErrBodyTooDeep, MaxBodyDepth)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(req.Body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: %s", ErrMalformedBody, ErrorTrailingData)
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case strings.HasPrefix(err.Error(), unknownFieldMessage):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldMessage), `"`)
+		return &models.FieldError{Field: field, Code: models.CodeUnknownField, Err: models.ErrUnknownField}
+	case errors.As(err, &typeErr) && len(typeErr.Field) > 0:
+		return &models.FieldError{Field: typeErr.Field, Code: models.CodeInvalidFormat, Err: fmt.Errorf("%w, expected %s", ErrInvalidType, typeErr.Type)}
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: %s at offset %d", ErrMalformedBody, syntaxErr.Error(), syntaxErr.Offset)
+	default:
+		return fmt.Errorf("%w: %s", ErrMalformedBody, err.Error())
+	}
+}
+
+// jsonDepth is how deeply the objects and arrays in body nest, found without decoding it
+func jsonDepth(body string) int {
+	depth, deepest := 0, 0
+	inString, escaped := false, false
+	for _, c := range []byte(body) {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > deepest {
+				deepest = depth
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return deepest
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	setupHandler(t)
+
+	tests := map[string]struct {
+		body   string
+		status int
+		code   string
+		field  string
+	}{
+		"valid":                {`{"email": "user1@domain.com"}`, http.StatusAccepted, "", ""},
+		"unknown field":        {`{"email": "user1@domain.com", "skils": []}`, http.StatusBadRequest, "unknown_field", "skils"},
+		"unknown nested field": {`{"email": "user1@domain.com", "experience": [{"company": "x", "job_title": "y", "start_date": "2020-01", "responsibilites": ["typo"]}]}`, http.StatusBadRequest, "unknown_field", "responsibilites"},
+		"legacy dates":         {`{"email": "user1@domain.com", "experience": [{"company": "x", "job_title": "y", "start_month": "January", "start_year": 2020}]}`, http.StatusAccepted, "", ""},
+		"wrong type":           {`{"email": 42}`, http.StatusBadRequest, "invalid_type", "email"},
+		"malformed":            {`{"email": `, http.StatusBadRequest, "malformed_body", ""},
+		"trailing data":        {`{"email": "user1@domain.com"} {}`, http.StatusBadRequest, "malformed_body", ""},
+		"too deep":             {`{"skills": ` + strings.Repeat("[", MaxBodyDepth+1) + strings.Repeat("]", MaxBodyDepth+1) + `}`, http.StatusBadRequest, "body_too_deep", ""},
+		"too large":            {`{"summary": "` + strings.Repeat("a", MaxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, "body_too_large", ""},
+	}
+
+	for name, test := range tests {
+		event := events.APIGatewayProxyRequest{
+			Resource:       "/user/{id}",
+			HTTPMethod:     "POST",
+			PathParameters: map[string]string{"id": "user1"},
+			RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+			Body:           test.body,
+		}
+
+		res, err := PutUser(context.Background(), event, svc, logger)
+		if err != nil {
+			t.Errorf("Failed to get a response for %s: %s", name, err.Error())
+			continue
+		} else if test.status != res.StatusCode {
+			t.Errorf("Expected status code for %s to be %d, but was %d: %s", name, test.status, res.StatusCode, res.Body)
+			continue
+		} else if len(test.code) == 0 {
+			continue
+		}
+
+		errorBody := &ErrorBody{}
+		if jsonErr := json.Unmarshal([]byte(res.Body), errorBody); jsonErr != nil {
+			t.Errorf("Failed to covert body to error body object: %s", jsonErr.Error())
+		} else if errorBody.Code == nil || test.code != *errorBody.Code {
+			t.Errorf("Expected code for %s to be '%s', but was %v", name, test.code, errorBody.Code)
+		} else if len(test.field) > 0 && (errorBody.Field == nil || test.field != *errorBody.Field) {
+			t.Errorf("Expected field for %s to be '%s', but was %v", name, test.field, errorBody.Field)
+		}
+	}
+}
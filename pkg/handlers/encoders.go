@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/bkimbrough88/resume-backend/pkg/render"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MediaTypeHTML        = "text/html"
+	MediaTypeJSON        = "application/json"
+	MediaTypeMarkdown    = "text/markdown"
+	MediaTypePDF         = "application/pdf"
+	MediaTypeProblemJSON = "application/problem+json"
+	MediaTypeProblemXML  = "application/problem+xml"
+	MediaTypeXML         = "application/xml"
+	MediaTypeYAML        = "application/yaml"
+)
+
+type Encoder struct {
+	MediaType string
+	Binary    bool
+	Encode    func(status int, body interface{}) ([]byte, error)
+}
+
+var encoders []Encoder
+
+// problemMediaTypes are what error bodies are sent as in place of the negotiated JSON or XML type. Accepting a problem
+// type is the same as accepting the type it extends.
+var problemMediaTypes = map[string]string{
+	MediaTypeJSON: MediaTypeProblemJSON,
+	MediaTypeXML:  MediaTypeProblemXML,
+}
+
+func init() {
+	RegisterEncoder(Encoder{MediaType: MediaTypeJSON, Encode: encodeJSON})
+	RegisterEncoder(Encoder{MediaType: MediaTypeYAML, Encode: encodeYAML})
+	RegisterEncoder(Encoder{MediaType: MediaTypeXML, Encode: encodeXML})
+	RegisterEncoder(Encoder{MediaType: MediaTypeMarkdown, Encode: encodeMarkdown})
+	RegisterEncoder(Encoder{MediaType: MediaTypeHTML, Encode: encodeHTML})
+	RegisterEncoder(Encoder{MediaType: MediaTypePDF, Binary: true, Encode: encodePDF})
+}
+
+// RegisterEncoder adds an encoder, replacing any already registered for the same media type. The first registered
+// encoder is the default when the request does not express a preference.
+func RegisterEncoder(encoder Encoder) {
+	for i, existing := range encoders {
+		if existing.MediaType == encoder.MediaType {
+			encoders[i] = encoder
+			return
+		}
+	}
+
+	encoders = append(encoders, encoder)
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateEncoder picks the registered encoder that best satisfies the Accept header, returning false when none of
+// the acceptable media types are registered
+func negotiateEncoder(accept string) (Encoder, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return encoders[0], true
+	}
+
+	ranges := parseAccept(accept)
+	for i, r := range ranges {
+		if r.quality <= 0 {
+			continue
+		}
+
+		// An encoder only takes the quality of its most specific range, so "*/*, application/*;q=0" excludes JSON
+		for _, encoder := range encoders {
+			if mostSpecificRange(ranges, encoder.MediaType) == i {
+				return encoder, true
+			}
+		}
+	}
+
+	return Encoder{}, false
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if len(mediaType) == 0 {
+			continue
+		}
+		for base, problemType := range problemMediaTypes {
+			if mediaType == problemType {
+				mediaType = base
+			}
+		}
+
+		r := acceptRange{mediaType: mediaType, quality: 1}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	// More specific ranges win ties so that "text/*, text/html" prefers HTML over whatever text type is first
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	return ranges
+}
+
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func mediaTypeMatches(pattern string, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return false
+}
+
+// mostSpecificRange returns the index of the range that sets mediaType's quality as RFC 7231 section 5.3.2 describes,
+// or -1 when no range matches it
+func mostSpecificRange(ranges []acceptRange, mediaType string) int {
+	best := -1
+	for i, r := range ranges {
+		if mediaTypeMatches(r.mediaType, mediaType) && (best < 0 || specificity(r.mediaType) > specificity(ranges[best].mediaType)) {
+			best = i
+		}
+	}
+
+	return best
+}
+
+func encodeJSON(_ int, body interface{}) ([]byte, error) {
+	return json.Marshal(body)
+}
+
+// encodeYAML goes through JSON so that the YAML keys and omitted fields always match the JSON representation
+func encodeYAML(_ int, body interface{}) ([]byte, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(jsonBody, node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(node)
+
+	return yaml.Marshal(node)
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+func encodeXML(_ int, body interface{}) ([]byte, error) {
+	root := xml.Name{Local: "response"}
+	if _, isError := body.(ErrorBody); isError {
+		root = xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).EncodeElement(body, xml.StartElement{Name: root}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeMarkdown(status int, body interface{}) ([]byte, error) {
+	return render.Markdown(documentFor(status, body)), nil
+}
+
+func encodeHTML(status int, body interface{}) ([]byte, error) {
+	return render.HTML(documentFor(status, body))
+}
+
+func encodePDF(status int, body interface{}) ([]byte, error) {
+	return render.PDF(documentFor(status, body)), nil
+}
+
+func documentFor(status int, body interface{}) render.Document {
+	switch b := body.(type) {
+	case SuccessBody:
+		if b.User != nil {
+			return render.FromUser(b.User)
+		}
+	case ErrorBody:
+		if b.ErrorMsg != nil {
+			return render.FromMessage(http.StatusText(status), *b.ErrorMsg)
+		}
+	}
+
+	return render.FromMessage(http.StatusText(status), "")
+}
+
+func getHeader(req events.APIGatewayProxyRequest, name string) string {
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	for key, values := range req.MultiValueHeaders {
+		if strings.EqualFold(key, name) {
+			return strings.Join(values, ",")
+		}
+	}
+
+	return ""
+}
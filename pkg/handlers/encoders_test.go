@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+func TestNegotiateEncoder(t *testing.T) {
+	cases := map[string]string{
+		"":                                  MediaTypeJSON,
+		"*/*":                               MediaTypeJSON,
+		"application/yaml":                  MediaTypeYAML,
+		"text/html,application/xhtml+xml":   MediaTypeHTML,
+		"text/*":                            MediaTypeMarkdown,
+		"text/*, text/html":                 MediaTypeHTML,
+		"application/json;q=0.5, text/html": MediaTypeHTML,
+		"application/pdf;q=0.9, */*;q=0.1":  MediaTypePDF,
+		"application/json;q=0, */*":         MediaTypeYAML,
+		"*/*;q=0, application/json":         MediaTypeJSON,
+		"application/*;q=0, */*":            MediaTypeMarkdown,
+		"*/*, application/json;q=0.1":       MediaTypeYAML,
+		"text/*;q=0.5, text/html;q=0":       MediaTypeMarkdown,
+		"APPLICATION/XML":                   MediaTypeXML,
+		"image/png, text/markdown;q=0.2":    MediaTypeMarkdown,
+		"application/xml;charset=utf-8;q=1": MediaTypeXML,
+		"application/problem+json":          MediaTypeJSON,
+	}
+
+	for accept, expected := range cases {
+		if encoder, ok := negotiateEncoder(accept); !ok {
+			t.Errorf("Expected accept header '%s' to match an encoder", accept)
+		} else if expected != encoder.MediaType {
+			t.Errorf("Expected accept header '%s' to select '%s', but was '%s'", accept, expected, encoder.MediaType)
+		}
+	}
+
+	for _, accept := range []string{"image/png", "*/*;q=0", "application/*;q=0, text/*;q=0, */*;q=0.5"} {
+		if _, ok := negotiateEncoder(accept); ok {
+			t.Errorf("Expected accept header '%s' to not match any encoder", accept)
+		}
+	}
+}
+
+func TestApiResponseNegotiation(t *testing.T) {
+	logger, _ = zap.NewDevelopment()
+	body := SuccessBody{User: &models.User{UserId: "user", Email: "user@domain.com", GivenName: "John", SurName: "Doe"}}
+
+	req := events.APIGatewayProxyRequest{Headers: map[string]string{"accept": MediaTypeYAML}}
+	if res, err := apiResponse(req, http.StatusOK, body, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else {
+		if MediaTypeYAML != res.Headers[contentType] {
+			t.Errorf("Expected %s header to be '%s', but was '%s'", contentType, MediaTypeYAML, res.Headers[contentType])
+		}
+
+		if !strings.Contains(res.Body, "user_id: user") {
+			t.Errorf("Expected YAML body to use the JSON field names, but was '%s'", res.Body)
+		}
+	}
+
+	req.Headers["accept"] = MediaTypeXML
+	if res, err := apiResponse(req, http.StatusOK, body, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else if !strings.Contains(res.Body, "<response><user><user_id>user</user_id>") {
+		t.Errorf("Expected XML body to contain the user, but was '%s'", res.Body)
+	}
+
+	req.Headers["accept"] = MediaTypeHTML
+	if res, err := apiResponse(req, http.StatusOK, body, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else if !strings.Contains(res.Body, "<h1>John Doe</h1>") {
+		t.Errorf("Expected HTML body to contain the name as a heading, but was '%s'", res.Body)
+	}
+
+	req.Headers["accept"] = MediaTypePDF
+	if res, err := apiResponse(req, http.StatusOK, body, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else if !res.IsBase64Encoded {
+		t.Errorf("Expected PDF body to be base64 encoded")
+	} else if pdf, decodeErr := base64.StdEncoding.DecodeString(res.Body); decodeErr != nil {
+		t.Errorf("Failed to decode PDF body: %s", decodeErr.Error())
+	} else if !strings.HasPrefix(string(pdf), "%PDF-") {
+		t.Errorf("Expected body to be a PDF document")
+	}
+
+	req.Headers["accept"] = "image/png"
+	if res, err := apiResponse(req, http.StatusOK, body, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else {
+		if http.StatusNotAcceptable != res.StatusCode {
+			t.Errorf("Expected status code to be %d, but was %d", http.StatusNotAcceptable, res.StatusCode)
+		}
+
+		if MediaTypeProblemJSON != res.Headers[contentType] {
+			t.Errorf("Expected %s header to be '%s', but was '%s'", contentType, MediaTypeProblemJSON, res.Headers[contentType])
+		}
+	}
+
+	req.Headers["accept"] = MediaTypeMarkdown
+	errorBody := ErrorBody{ErrorMsg: aws.String("bad request")}
+	if res, err := apiResponse(req, http.StatusBadRequest, errorBody, logger); err != nil {
+		t.Errorf("Failed to get API response: %s", err.Error())
+	} else if !strings.Contains(res.Body, "bad request") {
+		t.Errorf("Expected markdown body to contain the error, but was '%s'", res.Body)
+	}
+}
@@ -1,78 +1,185 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"go.uber.org/zap"
+	"mime"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/ats"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
 	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/tailor"
 )
 
 const (
 	ErrorMethodNotAllowed  = "method not allowed"
+	ErrorNotAcceptable     = "none of the accepted media types are supported"
+	ErrorUserIdMismatch    = "user_id in body does not match the path"
 	ErrorUserIdNotProvided = "userId not provided"
 	ErrorUserNotProvided   = "user not provided in body"
+	ErrorValidationFailed  = "validation failed"
 )
 
 type SuccessBody struct {
-	User *models.User `json:"user,omitempty"`
+	User   *models.User   `json:"user,omitempty" xml:"user,omitempty"`
+	Share  *models.Share  `json:"share,omitempty" xml:"share,omitempty"`
+	Token  *string        `json:"token,omitempty" xml:"token,omitempty"`
+	ApiKey *models.ApiKey `json:"api_key,omitempty" xml:"api_key,omitempty"`
+	Key    *string        `json:"key,omitempty" xml:"key,omitempty"`
+
+	Organization *models.Organization `json:"organization,omitempty" xml:"organization,omitempty"`
+	Members      []models.Membership  `json:"members,omitempty" xml:"members>member,omitempty"`
+
+	Resume  *models.Resume  `json:"resume,omitempty" xml:"resume,omitempty"`
+	Resumes []models.Resume `json:"resumes,omitempty" xml:"resumes>resume,omitempty"`
+
+	Tailoring *tailor.Result `json:"tailoring,omitempty" xml:"tailoring,omitempty"`
+	AtsReport *ats.Report    `json:"ats_report,omitempty" xml:"ats_report,omitempty"`
 }
 
+// ErrorBody is an RFC 7807 problem document. Handlers only need to set the detail (ErrorMsg) and anything specific to
+// the failure; apiResponse fills in the type, title, status, instance and request ID. Code, Field, Line and Errors are
+// extension members.
 type ErrorBody struct {
-	ErrorMsg *string `json:"error,omitempty"`
+	Type       *string     `json:"type,omitempty" xml:"type,omitempty"`
+	Title      *string     `json:"title,omitempty" xml:"title,omitempty"`
+	Status     *int        `json:"status,omitempty" xml:"status,omitempty"`
+	ErrorMsg   *string     `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance   *string     `json:"instance,omitempty" xml:"instance,omitempty"`
+	RequestId  *string     `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Code       *string     `json:"code,omitempty" xml:"code,omitempty"`
+	Field      *string     `json:"field,omitempty" xml:"field,omitempty"`
+	Line       *int        `json:"line,omitempty" xml:"line,omitempty"`
+	Violations []Violation `json:"errors,omitempty" xml:"errors>error,omitempty"`
+
+	// RetryAfter is sent as the Retry-After header, in seconds
+	RetryAfter *int `json:"-" xml:"-"`
+}
+
+// Violation is one of the problems found when a body fails validation
+type Violation struct {
+	Field   string `json:"field" xml:"field"`
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
+	Line    *int   `json:"line,omitempty" xml:"line,omitempty"`
 }
 
-func GetUser(req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+func GetUser(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
 	userId := req.PathParameters["id"]
 	if len(userId) > 0 {
-		key := &models.UserKey{UserId: userId}
-		user, err := models.GetUserByKey(key, svc, logger)
+		if err := requireScope(req, auth.ScopeReadResume); err != nil {
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+
+		key := &models.UserKey{TenantId: tenantFromRequest(req), UserId: userId}
+		user, err := models.GetUserByKey(ctx, key, svc, logger)
 		if err != nil {
-			return apiResponse(getErrorStatusCode(err), ErrorBody{aws.String(err.Error())}, logger)
+			return apiResponse(req, getErrorStatusCode(err), ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
 		}
 
-		return apiResponse(http.StatusOK, SuccessBody{User: user}, logger)
+		return apiResponse(req, http.StatusOK, SuccessBody{User: models.Redact(user, accessLevel(ctx, req, user, svc, logger))}, logger)
 	} else {
-		return apiResponse(http.StatusBadRequest, ErrorBody{aws.String(ErrorUserIdNotProvided)}, logger)
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
 	}
 }
 
-func PutUser(req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+func PutUser(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
 	if len(req.Body) > 0 {
 		user := &models.User{}
-		if err := json.Unmarshal([]byte(req.Body), user); err != nil {
+		var doc *models.UserDocument
+		if isYAMLContentType(getHeader(req, "Content-Type")) {
+			if err := checkBody(req); err != nil {
+				return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+			}
+
+			var err error
+			if doc, err = models.UnmarshalUserYAML([]byte(req.Body)); err != nil {
+				logger.Error("Failed to unmarshal YAML body into User object", zap.Error(err), zap.String("body", req.Body))
+				return apiResponse(req, http.StatusBadRequest, newErrorBody(err), logger)
+			}
+			user = doc.User
+		} else if err := decodeJSON(req, user); err != nil {
 			logger.Error("Failed to unmarshal body into User object", zap.Error(err), zap.String("body", req.Body))
-			return apiResponse(getErrorStatusCode(err), ErrorBody{aws.String(err.Error())}, logger)
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+
+		if pathUserId := req.PathParameters["id"]; len(pathUserId) > 0 {
+			if len(user.UserId) == 0 {
+				user.UserId = pathUserId
+			} else if user.UserId != pathUserId {
+				logger.Error("Body user_id does not match path", zap.String("path_user_id", pathUserId), zap.String("user_id", user.UserId))
+				return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdMismatch)}, logger)
+			}
+		}
+
+		if principal, ok := PrincipalFromRequest(req); ok && len(user.UserId) == 0 {
+			user.UserId = principal.UserId
+		}
+		user.TenantId = tenantFromRequest(req)
+
+		if err := authorize(ctx, req, svc, user.UserId, logger); err != nil {
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
 		}
 
-		if err := models.PutUser(user, svc, logger); err != nil {
-			return apiResponse(getErrorStatusCode(err), ErrorBody{aws.String(err.Error())}, logger)
+		if len(user.OrgId) > 0 {
+			if err := authorizeResumeOrg(ctx, req, svc, user, logger); err != nil {
+				return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+			}
 		}
 
-		return apiResponse(http.StatusAccepted, SuccessBody{}, logger)
+		if err := models.PutUser(ctx, user, svc, logger); err != nil {
+			body := newErrorBody(err)
+			if doc != nil {
+				for i := range body.Violations {
+					body.Violations[i].Line = aws.Int(doc.Line(body.Violations[i].Field))
+				}
+			}
+			return apiResponse(req, getErrorStatusCode(err), body, logger)
+		}
+
+		return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
 	} else {
-		return apiResponse(http.StatusBadRequest, ErrorBody{aws.String(ErrorUserNotProvided)}, logger)
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserNotProvided)}, logger)
+	}
+}
+
+func isYAMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch mediaType {
+	case MediaTypeYAML, "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	default:
+		return false
 	}
 }
 
-func DeleteUser(req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+func DeleteUser(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
 	userId := req.PathParameters["id"]
 	if len(userId) > 0 {
-		key := &models.UserKey{UserId: userId}
-		if err := models.DeleteUser(key, svc, logger); err != nil {
-			return apiResponse(getErrorStatusCode(err), ErrorBody{aws.String(err.Error())}, logger)
+		if err := authorize(ctx, req, svc, userId, logger); err != nil {
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+
+		key := &models.UserKey{TenantId: tenantFromRequest(req), UserId: userId}
+		if err := models.DeleteUser(ctx, key, svc, logger); err != nil {
+			return apiResponse(req, getErrorStatusCode(err), ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
 		}
 
-		return apiResponse(http.StatusAccepted, SuccessBody{}, logger)
+		return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
 	} else {
-		return apiResponse(http.StatusBadRequest, ErrorBody{aws.String(ErrorUserIdNotProvided)}, logger)
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
 	}
 }
 
 func UnhandledMethod(req events.APIGatewayProxyRequest, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
 	logger.Warn("Method not allowed", zap.String("method", req.HTTPMethod))
-	return apiResponse(http.StatusMethodNotAllowed, ErrorBody{ErrorMsg: aws.String(ErrorMethodNotAllowed)}, logger)
+	return apiResponse(req, http.StatusMethodNotAllowed, ErrorBody{ErrorMsg: aws.String(ErrorMethodNotAllowed)}, logger)
 }
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
@@ -70,12 +72,19 @@ func TestGetUser(t *testing.T) {
 		Body:            "",
 		IsBase64Encoded: false,
 	}
-	if res, err := GetUser(event, svc, logger); err != nil {
+	if res, err := GetUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
 	} else if http.StatusOK != res.StatusCode {
 		t.Errorf("Expected status code to be %d, but was %d", http.StatusOK, res.StatusCode)
+	} else {
+		successBody := &SuccessBody{}
+		if jsonErr := json.Unmarshal([]byte(res.Body), successBody); jsonErr != nil {
+			t.Errorf("Failed to covert body to success body object: %s", jsonErr.Error())
+		} else if successBody.User == nil || len(successBody.User.Email) != 0 {
+			t.Errorf("Expected email to be redacted from the public view")
+		}
 	}
 
 	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
@@ -83,7 +92,7 @@ func TestGetUser(t *testing.T) {
 			Item: map[string]*dynamodb.AttributeValue{},
 		}, nil
 	}
-	if res, err := GetUser(event, svc, logger); err != nil {
+	if res, err := GetUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -104,7 +113,7 @@ func TestGetUser(t *testing.T) {
 		Body:            "",
 		IsBase64Encoded: false,
 	}
-	if res, err := GetUser(event, svc, logger); err != nil {
+	if res, err := GetUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -136,6 +145,7 @@ func TestPutUser(t *testing.T) {
 		HTTPMethod: "POST",
 		RequestContext: events.APIGatewayProxyRequestContext{
 			ResourceID:   "POST /user",
+			Authorizer:   authorizerFor("user1"),
 			Stage:        "v1",
 			ResourcePath: "/user",
 			HTTPMethod:   "POST",
@@ -143,7 +153,7 @@ func TestPutUser(t *testing.T) {
 		Body:            string(userStr),
 		IsBase64Encoded: false,
 	}
-	if res, err := PutUser(event, svc, logger); err != nil {
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -152,27 +162,39 @@ func TestPutUser(t *testing.T) {
 	}
 
 	user.Email = "not an email"
+	user.Github = "github.com/user"
 	userStr, _ = json.Marshal(user)
 	event.Body = string(userStr)
-	if res, err := PutUser(event, svc, logger); err != nil {
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
 	} else {
-		if http.StatusBadRequest != res.StatusCode {
-			t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+		if http.StatusUnprocessableEntity != res.StatusCode {
+			t.Errorf("Expected status code to be %d, but was %d", http.StatusUnprocessableEntity, res.StatusCode)
 		}
 
 		errorBody := &ErrorBody{}
 		if jsonErr := json.Unmarshal([]byte(res.Body), errorBody); jsonErr != nil {
 			t.Errorf("Failed to covert body to error body object: %s", jsonErr.Error())
-		} else if models.ErrorInvalidEmail != *errorBody.ErrorMsg {
-			t.Errorf("Expected error to be '%s', but was '%s'", models.ErrorInvalidEmail, *errorBody.ErrorMsg)
+		} else if ErrorValidationFailed != *errorBody.ErrorMsg {
+			t.Errorf("Expected error to be '%s', but was '%s'", ErrorValidationFailed, *errorBody.ErrorMsg)
+		} else if len(errorBody.Violations) != 2 {
+			t.Errorf("Expected every violation to be reported, but was %+v", errorBody.Violations)
+		} else {
+			if "email" != errorBody.Violations[0].Field || models.CodeInvalidFormat != errorBody.Violations[0].Code || models.ErrorInvalidEmail != errorBody.Violations[0].Message {
+				t.Errorf("Expected the first violation to be for the email, but was %+v", errorBody.Violations[0])
+			}
+
+			if "github" != errorBody.Violations[1].Field || models.ErrorInvalidUrl != errorBody.Violations[1].Message {
+				t.Errorf("Expected the second violation to be for the github link, but was %+v", errorBody.Violations[1])
+			}
 		}
 	}
+	user.Github = ""
 
 	event.Body = ""
-	if res, err := PutUser(event, svc, logger); err != nil {
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -190,6 +212,88 @@ func TestPutUser(t *testing.T) {
 	}
 }
 
+func TestPutUserYAML(t *testing.T) {
+	setupHandler(t)
+
+	event := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}",
+		Path:       "/v1/user/user1",
+		HTTPMethod: "POST",
+		Headers: map[string]string{
+			"Content-Type": "application/yaml; charset=utf-8",
+		},
+		PathParameters: map[string]string{
+			"id": "user1",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			ResourceID:   "POST /user/{id}",
+			Authorizer:   authorizerFor("user1"),
+			Stage:        "v1",
+			ResourcePath: "/user/{id}",
+			HTTPMethod:   "POST",
+		},
+		Body:            "email: user1@domain.com\ngiven_name: John\n",
+		IsBase64Encoded: false,
+	}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if input.Item["user_id"] == nil || "user1" != *input.Item["user_id"].S {
+			t.Errorf("Expected user_id to be taken from the path")
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusAccepted, res.StatusCode)
+	}
+
+	event.Body = "given_name: John\nemail: not an email\nskills:\n  - name: Go\n  - name: Go\n"
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else {
+		if http.StatusUnprocessableEntity != res.StatusCode {
+			t.Errorf("Expected status code to be %d, but was %d", http.StatusUnprocessableEntity, res.StatusCode)
+		}
+
+		errorBody := &ErrorBody{}
+		if jsonErr := json.Unmarshal([]byte(res.Body), errorBody); jsonErr != nil {
+			t.Errorf("Failed to covert body to error body object: %s", jsonErr.Error())
+		} else if len(errorBody.Violations) != 2 {
+			t.Errorf("Expected 2 violations, but was %+v", errorBody.Violations)
+		} else {
+			if "email" != errorBody.Violations[0].Field || errorBody.Violations[0].Line == nil || 2 != *errorBody.Violations[0].Line {
+				t.Errorf("Expected the email violation on line 2, but was %+v", errorBody.Violations[0])
+			}
+
+			if "skills[1]" != errorBody.Violations[1].Field || models.CodeDuplicate != errorBody.Violations[1].Code || errorBody.Violations[1].Line == nil || 5 != *errorBody.Violations[1].Line {
+				t.Errorf("Expected the duplicate skill on line 5, but was %+v", errorBody.Violations[1])
+			}
+		}
+	}
+
+	event.Body = "user_id: someone-else\nemail: user1@domain.com\n"
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	event.Body = "email: [unterminated"
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
 func TestDeleteUser(t *testing.T) {
 	setupHandler(t)
 
@@ -202,6 +306,7 @@ func TestDeleteUser(t *testing.T) {
 		},
 		RequestContext: events.APIGatewayProxyRequestContext{
 			ResourceID:   "DELETE /user/{id}",
+			Authorizer:   authorizerFor("user1"),
 			Stage:        "v1",
 			ResourcePath: "/user/{id}",
 			HTTPMethod:   "DELETE",
@@ -209,7 +314,7 @@ func TestDeleteUser(t *testing.T) {
 		Body:            "",
 		IsBase64Encoded: false,
 	}
-	if res, err := DeleteUser(event, svc, logger); err != nil {
+	if res, err := DeleteUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -221,7 +326,7 @@ func TestDeleteUser(t *testing.T) {
 	mocks.DeleteItemMock = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 		return nil, errors.New(expectedError)
 	}
-	if res, err := DeleteUser(event, svc, logger); err != nil {
+	if res, err := DeleteUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -244,6 +349,7 @@ func TestDeleteUser(t *testing.T) {
 		HTTPMethod: "DELETE",
 		RequestContext: events.APIGatewayProxyRequestContext{
 			ResourceID:   "DELETE /user",
+			Authorizer:   authorizerFor("user1"),
 			Stage:        "v1",
 			ResourcePath: "/user",
 			HTTPMethod:   "DELETE",
@@ -251,7 +357,7 @@ func TestDeleteUser(t *testing.T) {
 		Body:            "",
 		IsBase64Encoded: false,
 	}
-	if res, err := DeleteUser(event, svc, logger); err != nil {
+	if res, err := DeleteUser(context.Background(), event, svc, logger); err != nil {
 		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to have a response, but it was nil")
@@ -305,3 +411,21 @@ func TestMethodNotAllowed(t *testing.T) {
 		}
 	}
 }
+
+func TestGetUserTimeout(t *testing.T) {
+	setupHandler(t)
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "user1"},
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if res, err := GetUser(ctx, event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetUser: %s", err.Error())
+	} else if http.StatusGatewayTimeout != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusGatewayTimeout, res.StatusCode)
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorIdempotencyKeyInProgress = "a request with this idempotency key is still in progress"
+	ErrorIdempotencyKeyReused     = "idempotency key was already used for a different request"
+
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	DefaultIdempotencyTTL     = 24 * time.Hour
+	idempotencyLease          = time.Minute
+	idempotencySettleTimeout  = time.Second
+	maxIdempotencyKeyLength   = 255
+	anonymousIdempotencyScope = "anonymous"
+)
+
+var (
+	ErrIdempotencyKeyInProgress = errors.New(ErrorIdempotencyKeyInProgress)
+	ErrIdempotencyKeyReused     = errors.New(ErrorIdempotencyKeyReused)
+)
+
+// idempotentMethods are the methods an Idempotency-Key is honored on. Reads are already safe to retry.
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Idempotency replays the first response to a write made with an Idempotency-Key header when the client retries it,
+// for ttl after the first request. Keys are scoped to the caller, and reusing one for a different request is
+// rejected. A request that errors or fails with a 5xx releases its key so it can be retried for real. While the
+// request runs the key is only held for idempotencyLease, longer than API Gateway waits for a response, so a claim
+// left behind by a function that was killed part way through doesn't block retries for the whole ttl. Add it after
+// Authenticate so the caller is known.
+func Idempotency(ttl time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+			key := getHeader(req, IdempotencyKeyHeader)
+			if len(key) == 0 || !idempotentMethods[req.HTTPMethod] {
+				return next(ctx, req, svc, logger)
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				return apiResponse(req, getErrorStatusCode(models.ErrInvalidIdempotencyKey), newErrorBody(models.ErrInvalidIdempotencyKey), logger)
+			}
+
+			now := time.Now()
+			record := &models.IdempotencyRecord{
+				TenantId:    tenantFromRequest(req),
+				Key:         idempotencyScope(req) + "#" + key,
+				Fingerprint: requestFingerprint(req),
+				ExpiresAt:   now.Add(idempotencyLease).Unix(),
+			}
+			claim := *record
+
+			if err := models.ClaimIdempotencyKey(ctx, &claim, now, svc, logger); errors.Is(err, models.ErrIdempotencyKeyInUse) {
+				recordKey := &models.IdempotencyKey{TenantId: record.TenantId, Key: record.Key}
+				return replay(ctx, req, recordKey, record.Fingerprint, now, svc, logger)
+			} else if err != nil {
+				return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+			}
+
+			res, err := next(ctx, req, svc, logger)
+
+			// The request's context may be what failed it, so the key is settled on one of its own
+			settleCtx, cancel := context.WithTimeout(context.Background(), idempotencySettleTimeout)
+			defer cancel()
+
+			if err != nil || res == nil || res.StatusCode >= http.StatusInternalServerError {
+				releaseIdempotencyKey(settleCtx, &claim, svc, logger)
+				return res, err
+			}
+
+			record.ExpiresAt = now.Add(ttl).Unix()
+			record.Completed = true
+			record.StatusCode = res.StatusCode
+			record.Headers = res.Headers
+			record.Body = res.Body
+			record.IsBase64Encoded = res.IsBase64Encoded
+			if err := models.PutIdempotencyRecord(settleCtx, record, &claim, svc, logger); errors.Is(err, models.ErrIdempotencyKeyInUse) {
+				logger.Warn("Lease on idempotency key ran out before the response was stored")
+			} else if err != nil {
+				// Otherwise retries would be told the request is still in progress until the claim expires
+				logger.Error("Failed to store response for idempotency key", zap.Error(err))
+				releaseIdempotencyKey(settleCtx, &claim, svc, logger)
+			}
+
+			return res, nil
+		}
+	}
+}
+
+// releaseIdempotencyKey deletes the claim unless its lease ran out and another request has the key now
+func releaseIdempotencyKey(ctx context.Context, claim *models.IdempotencyRecord, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) {
+	if err := models.DeleteIdempotencyRecord(ctx, claim, svc, logger); errors.Is(err, models.ErrIdempotencyKeyInUse) {
+		logger.Warn("Lease on idempotency key ran out before it was released")
+	} else if err != nil {
+		logger.Error("Failed to release idempotency key", zap.Error(err))
+	}
+}
+
+// replay answers a request whose key is already held with the stored response, if it is the same request and the
+// first one has finished
+func replay(ctx context.Context, req events.APIGatewayProxyRequest, key *models.IdempotencyKey, fingerprint string, now time.Time, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	record, err := models.GetIdempotencyRecord(ctx, key, now, svc, logger)
+	if errors.Is(err, models.ErrNoResultsFound) {
+		// The holder released the key between our claim and read
+		err = ErrIdempotencyKeyInProgress
+	}
+	if err == nil && record.Fingerprint != fingerprint {
+		err = ErrIdempotencyKeyReused
+	}
+	if err == nil && !record.Completed {
+		err = ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		logger.Warn("Rejected idempotent request", zap.Error(err))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	headers := make(map[string]string, len(record.Headers)+1)
+	for name, value := range record.Headers {
+		headers[name] = value
+	}
+	if len(req.RequestContext.RequestID) > 0 {
+		headers[RequestIdHeader] = req.RequestContext.RequestID
+	}
+	headers[IdempotentReplayedHeader] = "true"
+
+	logger.Info("Replayed response for idempotency key", zap.Int("status_code", record.StatusCode))
+	return &events.APIGatewayProxyResponse{
+		StatusCode:      record.StatusCode,
+		Headers:         headers,
+		Body:            record.Body,
+		IsBase64Encoded: record.IsBase64Encoded,
+	}, nil
+}
+
+// idempotencyScope keeps callers from seeing each other's responses by guessing keys
+func idempotencyScope(req events.APIGatewayProxyRequest) string {
+	if principal, ok := PrincipalFromRequest(req); ok {
+		return principal.Subject
+	}
+
+	return anonymousIdempotencyScope
+}
+
+// requestFingerprint identifies the request a key was first used for
+func requestFingerprint(req events.APIGatewayProxyRequest) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.HTTPMethod + "\n" + req.Path + "\n"))
+	hash.Write([]byte(req.Body))
+	return hex.EncodeToString(hash.Sum(nil))
+}
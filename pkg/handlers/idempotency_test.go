@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+// mockIdempotencyTable keeps idempotency records in memory, honoring the conditions on claiming and settling a key
+func mockIdempotencyTable() map[string]map[string]*dynamodb.AttributeValue {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		pk := *input.Item[models.PartitionKey].S
+		if conditionFailed(items[pk], input.ExpressionAttributeValues) {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+		}
+		items[pk] = input.Item
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: items[*input.Key[models.PartitionKey].S]}, nil
+	}
+	mocks.DeleteItemMock = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		pk := *input.Key[models.PartitionKey].S
+		if conditionFailed(items[pk], input.ExpressionAttributeValues) {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+		}
+		delete(items, pk)
+		return &dynamodb.DeleteItemOutput{}, nil
+	}
+	return items
+}
+
+// conditionFailed checks an existing record against either a claim, which needs it to have expired, or a settle, which
+// needs it to still be the same claim
+func conditionFailed(existing map[string]*dynamodb.AttributeValue, values map[string]*dynamodb.AttributeValue) bool {
+	if now, ok := values[":now"]; ok {
+		if existing == nil {
+			return false
+		}
+		expiresAt, _ := strconv.ParseInt(*existing["expires_at"].N, 10, 64)
+		claimTime, _ := strconv.ParseInt(*now.N, 10, 64)
+		return expiresAt > claimTime
+	}
+	if lease, ok := values[":lease"]; ok {
+		return existing == nil || *existing["fingerprint"].S != *values[":fingerprint"].S ||
+			*existing["expires_at"].N != *lease.N || existing["completed"] != nil && *existing["completed"].BOOL
+	}
+	return false
+}
+
+func TestIdempotency(t *testing.T) {
+	setupHandler(t)
+	mockIdempotencyTable()
+
+	calls := 0
+	status := http.StatusCreated
+	handler := Idempotency(time.Hour)(func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		calls++
+		return apiResponse(req, status, SuccessBody{Key: &req.Body}, logger)
+	})
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Path:           "/user/user1/api-keys",
+		Headers:        map[string]string{IdempotencyKeyHeader: "key1"},
+		Body:           "first",
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1"), RequestID: "request-1"},
+	}
+	first, _ := handler(context.Background(), event, svc, logger)
+
+	event.RequestContext.RequestID = "request-2"
+	if res, err := handler(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response: %s", err.Error())
+	} else {
+		if calls != 1 {
+			t.Errorf("Expected the handler to only be called once, but was called %d times", calls)
+		}
+		if res.StatusCode != first.StatusCode || res.Body != first.Body {
+			t.Errorf("Expected the first response to be replayed, but got %d: %s", res.StatusCode, res.Body)
+		}
+		if res.Headers[IdempotentReplayedHeader] != "true" || res.Headers[RequestIdHeader] != "request-2" {
+			t.Errorf("Expected the replay to be marked and carry the retry's request ID, but headers were %v", res.Headers)
+		}
+	}
+
+	event.Body = "second"
+	if res, _ := handler(context.Background(), event, svc, logger); http.StatusUnprocessableEntity != res.StatusCode {
+		t.Errorf("Expected status code for a reused key to be %d, but was %d", http.StatusUnprocessableEntity, res.StatusCode)
+	}
+
+	event.RequestContext.Authorizer = authorizerFor("user2")
+	if res, _ := handler(context.Background(), event, svc, logger); http.StatusCreated != res.StatusCode || calls != 2 {
+		t.Errorf("Expected another caller's key to be separate, but status code was %d", res.StatusCode)
+	}
+
+	event.Headers[IdempotencyKeyHeader] = "key2"
+	status = http.StatusServiceUnavailable
+	handler(context.Background(), event, svc, logger)
+	status = http.StatusCreated
+	if res, _ := handler(context.Background(), event, svc, logger); http.StatusCreated != res.StatusCode || calls != 4 {
+		t.Errorf("Expected a key to be released after a failure, but status code was %d after %d calls", res.StatusCode, calls)
+	}
+
+	event.HTTPMethod = "GET"
+	handler(context.Background(), event, svc, logger)
+	if calls != 5 {
+		t.Errorf("Expected reads to ignore the key, but the handler was called %d times", calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	setupHandler(t)
+	mockIdempotencyTable()
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod:     "DELETE",
+		Path:           "/user/user1",
+		Headers:        map[string]string{IdempotencyKeyHeader: "key1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+
+	var inner *events.APIGatewayProxyResponse
+	var handler HandlerFunc
+	handler = Idempotency(time.Hour)(func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		if inner == nil {
+			inner, _ = handler(ctx, req, svc, logger)
+		}
+		return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
+	})
+
+	handler(context.Background(), event, svc, logger)
+	if inner == nil || http.StatusConflict != inner.StatusCode {
+		t.Errorf("Expected a retry while the first request runs to get a conflict, but was %v", inner)
+	}
+
+	event.Headers[IdempotencyKeyHeader] = string(make([]byte, 256))
+	if res, _ := handler(context.Background(), event, svc, logger); http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code for an oversized key to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	setupHandler(t)
+	items := mockIdempotencyTable()
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Path:           "/user/user1/api-keys",
+		Headers:        map[string]string{IdempotencyKeyHeader: "key1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+
+	var claimedUntil int64
+	status := http.StatusCreated
+	handler := Idempotency(time.Hour)(func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		for _, item := range items {
+			claimedUntil, _ = strconv.ParseInt(*item["expires_at"].N, 10, 64)
+		}
+		return apiResponse(req, status, SuccessBody{}, logger)
+	})
+
+	start := time.Now()
+	handler(context.Background(), event, svc, logger)
+	if claimedUntil < start.Add(idempotencyLease).Unix() || claimedUntil > time.Now().Add(idempotencyLease).Unix() {
+		t.Errorf("Expected the claim to be held for the lease, but it expires at %d", claimedUntil)
+	}
+
+	for _, item := range items {
+		if expiresAt, _ := strconv.ParseInt(*item["expires_at"].N, 10, 64); expiresAt < start.Add(time.Hour).Unix() {
+			t.Errorf("Expected the stored response to be kept for the ttl, but it expires at %d", expiresAt)
+		}
+	}
+
+	// A request that ran out of time still releases its key
+	ctx, cancel := context.WithCancel(context.Background())
+	handler = Idempotency(time.Hour)(func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		cancel()
+		return apiResponse(req, http.StatusGatewayTimeout, SuccessBody{}, logger)
+	})
+	event.Headers[IdempotencyKeyHeader] = "key2"
+	handler(ctx, event, svc, logger)
+	if len(items) != 1 {
+		t.Errorf("Expected the key to be released after its request was cancelled, but there were %d records", len(items))
+	}
+}
+
+func TestIdempotencyLeaseLost(t *testing.T) {
+	setupHandler(t)
+	items := mockIdempotencyTable()
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Path:           "/user/user1/api-keys",
+		Headers:        map[string]string{IdempotencyKeyHeader: "key1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+
+	// The lease runs out while the handler is still going, and a retry claims the key for itself
+	status := http.StatusCreated
+	retryLease := time.Now().Add(2 * idempotencyLease).Unix()
+	handler := Idempotency(time.Hour)(func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		for _, item := range items {
+			item["expires_at"].N = aws.String(strconv.FormatInt(retryLease, 10))
+		}
+		return apiResponse(req, status, SuccessBody{}, logger)
+	})
+
+	if res, _ := handler(context.Background(), event, svc, logger); http.StatusCreated != res.StatusCode {
+		t.Errorf("Expected the response to still be returned, but status code was %d", res.StatusCode)
+	}
+	for _, item := range items {
+		if *item["completed"].BOOL || strconv.FormatInt(retryLease, 10) != *item["expires_at"].N {
+			t.Errorf("Expected the retry's claim to be kept, but the record was %v", item)
+		}
+	}
+
+	event.Headers[IdempotencyKeyHeader] = "key2"
+	status = http.StatusServiceUnavailable
+	handler(context.Background(), event, svc, logger)
+	if len(items) != 2 {
+		t.Errorf("Expected a failed request not to release the retry's claim, but there were %d records", len(items))
+	}
+}
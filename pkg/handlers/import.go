@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/linkedin"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorArchiveNotProvided = "LinkedIn export archive not provided in body"
+)
+
+// ImportLinkedin merges a LinkedIn data export into the user, creating the user if they do not exist yet
+func ImportLinkedin(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if len(req.Body) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorArchiveNotProvided)}, logger)
+	}
+
+	if err := checkBody(req); err != nil {
+		logger.Warn("Rejected oversized LinkedIn export", zap.Error(err))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	archive := []byte(req.Body)
+	if req.IsBase64Encoded {
+		var err error
+		if archive, err = base64.StdEncoding.DecodeString(req.Body); err != nil {
+			logger.Error("Failed to decode base64 body", zap.Error(err))
+			return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+		}
+	}
+
+	imported, err := linkedin.Parse(archive)
+	if err != nil {
+		logger.Error("Failed to parse LinkedIn export", zap.Error(err))
+		if errors.Is(err, linkedin.ErrArchiveTooLarge) {
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+	}
+
+	tenantId := tenantFromRequest(req)
+	existing, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: tenantId, UserId: userId}, svc, logger)
+	if err != nil && !errors.Is(err, models.ErrNoResultsFound) {
+		return apiResponse(req, getErrorStatusCode(err), ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+	} else if err != nil {
+		existing = &models.User{TenantId: tenantId, UserId: userId}
+	}
+
+	user := models.MergeUser(existing, imported)
+	user.TenantId = tenantId
+	if err := models.PutUser(ctx, user, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{User: user}, logger)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestImportLinkedin(t *testing.T) {
+	setupHandler(t)
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	f, _ := writer.Create("Positions.csv")
+	_, _ = f.Write([]byte("Company Name,Title,Description,Location,Started On,Finished On\nCo,SRE,,Place,May 2020,\n"))
+	_ = writer.Close()
+
+	event := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}/linkedin",
+		Path:       "/v1/user/user1/linkedin",
+		HTTPMethod: "POST",
+		PathParameters: map[string]string{
+			"id": "user1",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			ResourceID:   "POST /user/{id}/linkedin",
+			Authorizer:   authorizerFor("user1"),
+			Stage:        "v1",
+			ResourcePath: "/user/{id}/linkedin",
+			HTTPMethod:   "POST",
+		},
+		Body:            base64.StdEncoding.EncodeToString(buf.Bytes()),
+		IsBase64Encoded: true,
+	}
+
+	var stored *models.User
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		stored = &models.User{}
+		if err := dynamodbattribute.UnmarshalMap(input.Item, stored); err != nil {
+			t.Errorf("Failed to unmarshal stored user: %s", err.Error())
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if res, err := ImportLinkedin(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for ImportLinkedin: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if stored == nil || user.Email != stored.Email || len(stored.Experience) != 1 {
+		t.Errorf("Expected the import to be merged into the existing user, but stored %+v", stored)
+	}
+
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{}}, nil
+	}
+	if res, err := ImportLinkedin(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for ImportLinkedin: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else {
+		if http.StatusUnprocessableEntity != res.StatusCode {
+			t.Errorf("Expected status code to be %d, but was %d", http.StatusUnprocessableEntity, res.StatusCode)
+		}
+
+		errorBody := &ErrorBody{}
+		if jsonErr := json.Unmarshal([]byte(res.Body), errorBody); jsonErr != nil {
+			t.Errorf("Failed to covert body to error body object: %s", jsonErr.Error())
+		} else if len(errorBody.Violations) == 0 || "email" != errorBody.Violations[0].Field {
+			t.Errorf("Expected a new user without an email to fail validation on email")
+		}
+	}
+
+	event.Body = "bm90IGEgemlw"
+	if res, err := ImportLinkedin(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for ImportLinkedin: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	event.Body = strings.Repeat("A", MaxBodyBytes+4)
+	if res, err := ImportLinkedin(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for ImportLinkedin: %s", err.Error())
+	} else if res == nil {
+		t.Errorf("Expected to have a response, but it was nil")
+	} else if http.StatusRequestEntityTooLarge != res.StatusCode {
+		t.Errorf("Expected status code for an oversized body to be %d, but was %d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+}
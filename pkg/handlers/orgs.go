@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorOrgIdNotProvided   = "orgId not provided"
+	ErrorOrgNameNotProvided = "organization name not provided"
+)
+
+type CreateOrgRequest struct {
+	Name string `json:"name"`
+}
+
+type PutMemberRequest struct {
+	Role models.Role `json:"role"`
+}
+
+// CreateOrg creates an organization with the caller as its first owner
+func CreateOrg(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	principal, ok := PrincipalFromRequest(req)
+	if !ok {
+		return unauthorized(req, ErrorAuthenticationRequired, logger)
+	}
+
+	orgReq := &CreateOrgRequest{}
+	if err := decodeJSON(req, orgReq); err != nil {
+		logger.Error("Failed to unmarshal body into CreateOrgRequest object", zap.Error(err), zap.String("body", req.Body))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if len(orgReq.Name) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorOrgNameNotProvided), Field: aws.String("name")}, logger)
+	}
+
+	orgId, err := newOrgId()
+	if err != nil {
+		logger.Error("Failed to generate organization ID", zap.Error(err))
+		return apiResponse(req, http.StatusInternalServerError, ErrorBody{ErrorMsg: aws.String(err.Error())}, logger)
+	}
+
+	tenantId := tenantOf(principal)
+	org := &models.Organization{TenantId: tenantId, OrgId: orgId, Name: orgReq.Name, CreatedAt: time.Now().Unix(), OwnerCount: 1}
+	if err := models.PutOrganization(ctx, org, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	owner := models.Membership{TenantId: tenantId, OrgId: orgId, UserId: principal.UserId, Role: models.RoleOwner}
+	if err := models.PutMembership(ctx, &owner, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusCreated, SuccessBody{Organization: org, Members: []models.Membership{owner}}, logger)
+}
+
+// GetOrg returns the organization and its members to any of its members
+func GetOrg(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	orgId := req.PathParameters["orgId"]
+	if len(orgId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorOrgIdNotProvided)}, logger)
+	}
+
+	if err := authorizeOrg(ctx, req, svc, orgId, models.Role.IsValid, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	key := &models.OrganizationKey{TenantId: tenantFromRequest(req), OrgId: orgId}
+	org, err := models.GetOrganization(ctx, key, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	members, err := models.GetMemberships(ctx, key, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{Organization: org, Members: members}, logger)
+}
+
+// PutMember adds the user to the organization or changes their role. Only owners manage memberships.
+func PutMember(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	key, resp, err := memberPathParameters(req, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if err := authorizeOrg(ctx, req, svc, key.OrgId, models.Role.CanManage, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	memberReq := &PutMemberRequest{}
+	if err := decodeJSON(req, memberReq); err != nil {
+		logger.Error("Failed to unmarshal body into PutMemberRequest object", zap.Error(err), zap.String("body", req.Body))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	membership := &models.Membership{TenantId: key.TenantId, OrgId: key.OrgId, UserId: key.UserId, Role: memberReq.Role}
+	if err := models.SetMembershipRole(ctx, membership, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{Members: []models.Membership{*membership}}, logger)
+}
+
+// DeleteMember removes the user from the organization. Owners can remove anyone, and any member can leave, except for
+// the last owner.
+func DeleteMember(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	key, resp, err := memberPathParameters(req, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if principal, ok := PrincipalFromRequest(req); !ok || principal.UserId != key.UserId {
+		if err := authorizeOrg(ctx, req, svc, key.OrgId, models.Role.CanManage, logger); err != nil {
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+	}
+
+	if err := models.RemoveMembership(ctx, key, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
+}
+
+func memberPathParameters(req events.APIGatewayProxyRequest, logger *zap.Logger) (*models.MembershipKey, *events.APIGatewayProxyResponse, error) {
+	orgId := req.PathParameters["orgId"]
+	if len(orgId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorOrgIdNotProvided)}, logger)
+		return nil, resp, err
+	}
+
+	userId := req.PathParameters["userId"]
+	if len(userId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+		return nil, resp, err
+	}
+
+	return &models.MembershipKey{TenantId: tenantFromRequest(req), OrgId: orgId, UserId: userId}, nil, nil
+}
+
+// authorizeResumeOrg stops a resume from being moved into an organization the caller can't edit for. Resumes that
+// already belong to the organization can be saved by anyone allowed to modify them.
+func authorizeResumeOrg(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, user *models.User, logger *zap.Logger) error {
+	if existing, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: user.TenantId, UserId: user.UserId}, svc, logger); err == nil && existing.OrgId == user.OrgId {
+		return nil
+	}
+
+	return authorizeOrg(ctx, req, svc, user.OrgId, models.Role.CanEdit, logger)
+}
+
+func newOrgId() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+// setupOrg puts user1's resume in org1, where each user in roles is a member
+func setupOrg(t *testing.T, roles map[string]models.Role) {
+	setupHandler(t)
+	user.OrgId = "org1"
+
+	owners := 0
+	for _, role := range roles {
+		if role == models.RoleOwner {
+			owners++
+		}
+	}
+
+	userAttr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		switch *input.TableName {
+		case models.MembershipsTable:
+			userId := *input.Key["user_id"].S
+			if role, ok := roles[userId]; ok && "default#org1" == *input.Key[models.PartitionKey].S {
+				attr, _ := dynamodbattribute.MarshalMap(models.Membership{OrgId: "org1", UserId: userId, Role: role})
+				return &dynamodb.GetItemOutput{Item: attr}, nil
+			}
+			return &dynamodb.GetItemOutput{}, nil
+		case models.OrganizationsTable:
+			attr, _ := dynamodbattribute.MarshalMap(models.Organization{OrgId: "org1", Name: "Team", OwnerCount: owners})
+			return &dynamodb.GetItemOutput{Item: attr}, nil
+		default:
+			return &dynamodb.GetItemOutput{Item: userAttr}, nil
+		}
+	}
+
+	mocks.QueryMock = func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		var items []map[string]*dynamodb.AttributeValue
+		for userId, role := range roles {
+			attr, _ := dynamodbattribute.MarshalMap(models.Membership{OrgId: "org1", UserId: userId, Role: role})
+			items = append(items, attr)
+		}
+		return &dynamodb.QueryOutput{Items: items}, nil
+	}
+
+	// Membership changes are applied to roles, and refused the way DynamoDB would when the last owner would leave
+	mocks.TransactWriteItemsMock = func(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+		for _, item := range input.TransactItems {
+			if item.Update != nil && "owner_count > :one" == *item.Update.ConditionExpression && owners <= 1 {
+				return nil, &dynamodb.TransactionCanceledException{CancellationReasons: []*dynamodb.CancellationReason{
+					{Code: aws.String("None")},
+					{Code: aws.String("ConditionalCheckFailed")},
+				}}
+			}
+		}
+
+		for _, item := range input.TransactItems {
+			switch {
+			case item.Put != nil:
+				membership := models.Membership{}
+				_ = dynamodbattribute.UnmarshalMap(item.Put.Item, &membership)
+				roles[membership.UserId] = membership.Role
+			case item.Delete != nil:
+				delete(roles, *item.Delete.Key["user_id"].S)
+			case item.Update != nil:
+				change, _ := strconv.Atoi(*item.Update.ExpressionAttributeValues[":change"].N)
+				owners += change
+			}
+		}
+		return &dynamodb.TransactWriteItemsOutput{}, nil
+	}
+}
+
+func TestOrgRoles(t *testing.T) {
+	setupOrg(t, map[string]models.Role{"owner": models.RoleOwner, "editor": models.RoleEditor, "viewer": models.RoleViewer})
+
+	userStr, _ := json.Marshal(user)
+	getEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "user1"},
+	}
+	putEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		Body:           string(userStr),
+	}
+	deleteEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "DELETE",
+		PathParameters: map[string]string{"id": "user1"},
+	}
+
+	tests := map[string]struct {
+		fullResume  bool
+		writeStatus int
+	}{
+		"owner":      {true, http.StatusAccepted},
+		"editor":     {true, http.StatusAccepted},
+		"viewer":     {true, http.StatusForbidden},
+		"non-member": {false, http.StatusForbidden},
+	}
+
+	for caller, test := range tests {
+		authorizer := authorizerFor(caller)
+
+		getEvent.RequestContext.Authorizer = authorizer
+		body := &SuccessBody{}
+		if res, err := GetUser(context.Background(), getEvent, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for GetUser as %s: %s", caller, err.Error())
+		} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+			t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+		} else if test.fullResume != (user.Email == body.User.Email) {
+			t.Errorf("Expected %s to see the full resume to be %t", caller, test.fullResume)
+		}
+
+		putEvent.RequestContext.Authorizer = authorizer
+		if res, err := PutUser(context.Background(), putEvent, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for PutUser as %s: %s", caller, err.Error())
+		} else if test.writeStatus != res.StatusCode {
+			t.Errorf("Expected PutUser status code as %s to be %d, but was %d", caller, test.writeStatus, res.StatusCode)
+		}
+
+		deleteEvent.RequestContext.Authorizer = authorizer
+		if res, err := DeleteUser(context.Background(), deleteEvent, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for DeleteUser as %s: %s", caller, err.Error())
+		} else if test.writeStatus != res.StatusCode {
+			t.Errorf("Expected DeleteUser status code as %s to be %d, but was %d", caller, test.writeStatus, res.StatusCode)
+		}
+	}
+}
+
+func TestMoveResumeIntoOrg(t *testing.T) {
+	setupOrg(t, map[string]models.Role{"user1": models.RoleViewer})
+
+	// user1's stored resume is in org1, so moving it into org2 needs an editor role there
+	user.OrgId = "org2"
+	userStr, _ := json.Marshal(user)
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           string(userStr),
+	}
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	user.OrgId = "org1"
+	userStr, _ = json.Marshal(user)
+	event.Body = string(userStr)
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusAccepted, res.StatusCode)
+	}
+}
+
+func TestCreateOrg(t *testing.T) {
+	setupHandler(t)
+
+	var owner *models.Membership
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if models.MembershipsTable == *input.TableName {
+			owner = &models.Membership{}
+			_ = dynamodbattribute.UnmarshalMap(input.Item, owner)
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/orgs",
+		HTTPMethod:     "POST",
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           `{"name": "Team"}`,
+	}
+	body := &SuccessBody{}
+	if res, err := CreateOrg(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for CreateOrg: %s", err.Error())
+	} else if http.StatusCreated != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusCreated, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if body.Organization == nil || len(body.Organization.OrgId) == 0 {
+		t.Errorf("Expected the new organization in the response")
+	}
+
+	if owner == nil || "user1" != owner.UserId || models.RoleOwner != owner.Role {
+		t.Errorf("Expected the caller to be made owner, but membership was %+v", owner)
+	}
+
+	event.Body = `{}`
+	if res, err := CreateOrg(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for CreateOrg: %s", err.Error())
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	setupOrg(t, map[string]models.Role{"owner": models.RoleOwner, "editor": models.RoleEditor})
+
+	getEvent := events.APIGatewayProxyRequest{
+		Resource:       "/orgs/{orgId}",
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"orgId": "org1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("editor")},
+	}
+	body := &SuccessBody{}
+	if res, err := GetOrg(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetOrg: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if len(body.Members) != 2 {
+		t.Errorf("Expected 2 members, but there were %d", len(body.Members))
+	}
+
+	getEvent.RequestContext.Authorizer = authorizerFor("stranger")
+	if res, err := GetOrg(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetOrg: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	putEvent := events.APIGatewayProxyRequest{
+		Resource:       "/orgs/{orgId}/members/{userId}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"orgId": "org1", "userId": "user2"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("editor")},
+		Body:           `{"role": "viewer"}`,
+	}
+	if res, err := PutMember(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected an editor to be unable to manage members, but status code was %d", res.StatusCode)
+	}
+
+	putEvent.RequestContext.Authorizer = authorizerFor("owner")
+	if res, err := PutMember(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusAccepted, res.StatusCode, res.Body)
+	}
+
+	putEvent.Body = `{"role": "superuser"}`
+	if res, err := PutMember(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	deleteEvent := events.APIGatewayProxyRequest{
+		Resource:       "/orgs/{orgId}/members/{userId}",
+		HTTPMethod:     "DELETE",
+		PathParameters: map[string]string{"orgId": "org1", "userId": "owner"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("editor")},
+	}
+	if res, err := DeleteMember(context.Background(), deleteEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for DeleteMember: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	// Members can always leave
+	deleteEvent.PathParameters["userId"] = "editor"
+	if res, err := DeleteMember(context.Background(), deleteEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for DeleteMember: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusAccepted, res.StatusCode)
+	}
+
+	// Unless they are the last owner, who can neither leave nor step down
+	deleteEvent.PathParameters["userId"] = "owner"
+	deleteEvent.RequestContext.Authorizer = authorizerFor("owner")
+	if res, err := DeleteMember(context.Background(), deleteEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for DeleteMember: %s", err.Error())
+	} else if http.StatusConflict != res.StatusCode {
+		t.Errorf("Expected the last owner to be unable to leave, but status code was %d", res.StatusCode)
+	}
+
+	putEvent.PathParameters["userId"] = "owner"
+	putEvent.Body = `{"role": "editor"}`
+	if res, err := PutMember(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusConflict != res.StatusCode {
+		t.Errorf("Expected the last owner to be unable to step down, but status code was %d", res.StatusCode)
+	}
+
+	setupOrg(t, map[string]models.Role{"owner": models.RoleOwner, "other": models.RoleOwner})
+	if res, err := DeleteMember(context.Background(), deleteEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for DeleteMember: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected an owner to be able to leave when another owner remains, but status code was %d", res.StatusCode)
+	}
+}
+
+func TestOwnersSteppingDownTogether(t *testing.T) {
+	setupOrg(t, map[string]models.Role{"owner1": models.RoleOwner, "owner2": models.RoleOwner})
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/orgs/{orgId}/members/{userId}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"orgId": "org1", "userId": "owner1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("owner1")},
+		Body:           `{"role": "editor"}`,
+	}
+	if res, err := PutMember(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected the first owner to be able to step down, but status code was %d: %s", res.StatusCode, res.Body)
+	}
+
+	// The second owner was authorized before the first stepped down, but the count is checked as they write
+	event.PathParameters["userId"] = "owner2"
+	event.RequestContext.Authorizer = authorizerFor("owner2")
+	if res, err := PutMember(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutMember: %s", err.Error())
+	} else if http.StatusConflict != res.StatusCode {
+		t.Errorf("Expected the last owner to be unable to step down, but status code was %d", res.StatusCode)
+	}
+}
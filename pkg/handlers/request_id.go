@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+)
+
+// requestIdRegex limits the IDs taken from clients to ones that are safe to log and echo back
+var requestIdRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// WithRequestId settles on the ID of the request and returns a child logger that tags every line with it. The API
+// Gateway request ID is preferred, then the client's X-Request-Id, and otherwise a new one is generated. The ID is kept
+// in the request context so responses can echo it.
+func WithRequestId(req events.APIGatewayProxyRequest, logger *zap.Logger) (events.APIGatewayProxyRequest, *zap.Logger) {
+	if len(req.RequestContext.RequestID) == 0 {
+		if id := getHeader(req, RequestIdHeader); requestIdRegex.MatchString(id) {
+			req.RequestContext.RequestID = id
+		} else if id, err := newRequestId(); err == nil {
+			req.RequestContext.RequestID = id
+		} else {
+			logger.Warn("Failed to generate a request ID", zap.Error(err))
+			return req, logger
+		}
+	}
+
+	return req, logger.With(zap.String("request_id", req.RequestContext.RequestID))
+}
+
+func newRequestId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
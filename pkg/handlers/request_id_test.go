@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithRequestId(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+
+	req := events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"x-request-id": "client-id"},
+		RequestContext: events.APIGatewayProxyRequestContext{RequestID: "gateway-id"},
+	}
+	req, reqLogger := WithRequestId(req, base)
+	if req.RequestContext.RequestID != "gateway-id" {
+		t.Errorf("Expected the API Gateway request ID to be kept, but was '%s'", req.RequestContext.RequestID)
+	}
+
+	reqLogger.Info("handled")
+	if entries := logs.FilterField(zap.String("request_id", "gateway-id")).Len(); entries != 1 {
+		t.Errorf("Expected log lines to carry the request ID, but %d did", entries)
+	}
+
+	req = events.APIGatewayProxyRequest{Headers: map[string]string{"X-Request-Id": "client-id"}}
+	if req, _ = WithRequestId(req, base); req.RequestContext.RequestID != "client-id" {
+		t.Errorf("Expected the client's request ID to be used, but was '%s'", req.RequestContext.RequestID)
+	}
+
+	req = events.APIGatewayProxyRequest{Headers: map[string]string{"X-Request-Id": "bad id\n"}}
+	if req, _ = WithRequestId(req, base); len(req.RequestContext.RequestID) != 32 {
+		t.Errorf("Expected an unsafe client ID to be replaced with a generated one, but was '%s'", req.RequestContext.RequestID)
+	}
+
+	other, _ := WithRequestId(events.APIGatewayProxyRequest{}, base)
+	if len(other.RequestContext.RequestID) == 0 || other.RequestContext.RequestID == req.RequestContext.RequestID {
+		t.Errorf("Expected each request without an ID to get a new one, but was '%s'", other.RequestContext.RequestID)
+	}
+}
+
+func TestApiResponseRequestIdHeader(t *testing.T) {
+	req := events.APIGatewayProxyRequest{RequestContext: events.APIGatewayProxyRequestContext{RequestID: "request-1"}}
+	res, _ := apiResponse(req, http.StatusOK, SuccessBody{}, zap.NewNop())
+	if res.Headers[RequestIdHeader] != "request-1" {
+		t.Errorf("Expected %s header to be 'request-1', but was '%s'", RequestIdHeader, res.Headers[RequestIdHeader])
+	}
+
+	res, _ = apiResponse(events.APIGatewayProxyRequest{}, http.StatusOK, SuccessBody{}, zap.NewNop())
+	if _, ok := res.Headers[RequestIdHeader]; ok {
+		t.Errorf("Expected no %s header without a request ID", RequestIdHeader)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorResumeIdMismatch    = "resume_id in body does not match the path"
+	ErrorResumeIdNotProvided = "resumeId not provided"
+)
+
+// GetResume returns the user's profile as the resume selects it, redacted the same way GetUser is. Only callers who see
+// the full profile also get the resume's definition, since its selections name entries that may be hidden.
+func GetResume(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	key, resp, err := resumePathParameters(req, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if err := requireScope(req, auth.ScopeReadResume); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: key.TenantId, UserId: key.UserId}, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	resume, err := models.GetResume(ctx, key, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	level := accessLevel(ctx, req, user, svc, logger)
+	body := SuccessBody{User: models.Redact(models.ApplyResume(resume, user), level)}
+	if level == models.AccessOwner {
+		body.Resume = resume
+	}
+
+	return apiResponse(req, http.StatusOK, body, logger)
+}
+
+// GetResumes lists the user's resumes. Callers who don't see the full profile only get each resume's ID and name.
+func GetResumes(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := requireScope(req, auth.ScopeReadResume); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	key := &models.UserKey{TenantId: tenantFromRequest(req), UserId: userId}
+	user, err := models.GetUserByKey(ctx, key, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	resumes, err := models.GetResumes(ctx, key, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if accessLevel(ctx, req, user, svc, logger) != models.AccessOwner {
+		for i, resume := range resumes {
+			resumes[i] = models.Resume{UserId: resume.UserId, ResumeId: resume.ResumeId, Name: resume.Name}
+		}
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{Resumes: resumes}, logger)
+}
+
+func PutResume(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	key, resp, err := resumePathParameters(req, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if err := authorize(ctx, req, svc, key.UserId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	resume := &models.Resume{}
+	if err := decodeJSON(req, resume); err != nil {
+		logger.Error("Failed to unmarshal body into Resume object", zap.Error(err), zap.String("body", req.Body))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if len(resume.UserId) > 0 && resume.UserId != key.UserId {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdMismatch)}, logger)
+	}
+
+	if len(resume.ResumeId) > 0 && resume.ResumeId != key.ResumeId {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorResumeIdMismatch)}, logger)
+	}
+
+	resume.TenantId = key.TenantId
+	resume.UserId = key.UserId
+	resume.ResumeId = key.ResumeId
+
+	user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: key.TenantId, UserId: key.UserId}, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if err := models.ValidateResume(resume, user); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if err := models.PutResume(ctx, resume, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{Resume: resume}, logger)
+}
+
+func DeleteResume(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	key, resp, err := resumePathParameters(req, logger)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if err := authorize(ctx, req, svc, key.UserId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if err := models.DeleteResume(ctx, key, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
+}
+
+func resumePathParameters(req events.APIGatewayProxyRequest, logger *zap.Logger) (*models.ResumeKey, *events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+		return nil, resp, err
+	}
+
+	resumeId := req.PathParameters["resumeId"]
+	if len(resumeId) == 0 {
+		resp, err := apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorResumeIdNotProvided)}, logger)
+		return nil, resp, err
+	}
+
+	return &models.ResumeKey{TenantId: tenantFromRequest(req), UserId: userId, ResumeId: resumeId}, nil, nil
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestResumes(t *testing.T) {
+	setupHandler(t)
+	user.Summary = "Profile summary"
+	user.Experience = []models.Experience{{Company: "Co", JobTitle: "SRE"}, {Company: "Other Co", JobTitle: "Backend Engineer"}}
+
+	var stored *models.Resume
+	userAttr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		if models.ResumesTable == *input.TableName {
+			if stored == nil {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			attr, _ := dynamodbattribute.MarshalMap(stored)
+			return &dynamodb.GetItemOutput{Item: attr}, nil
+		}
+		return &dynamodb.GetItemOutput{Item: userAttr}, nil
+	}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		stored = &models.Resume{}
+		_ = dynamodbattribute.UnmarshalMap(input.Item, stored)
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	putEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/resumes/{resumeId}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1", "resumeId": "sre"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           `{"name": "SRE", "summary": "SRE summary", "experience": [{"company": "Co", "job_title": "SRE"}]}`,
+	}
+	if res, err := PutResume(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutResume: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusAccepted, res.StatusCode, res.Body)
+	}
+
+	if stored == nil || "user1" != stored.UserId || "sre" != stored.ResumeId {
+		t.Fatalf("Expected the resume to be stored under the path's IDs, but was %+v", stored)
+	}
+
+	getEvent := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/resumes/{resumeId}",
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "user1", "resumeId": "sre"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+	}
+	body := &SuccessBody{}
+	if res, err := GetResume(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetResume: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else {
+		if "SRE summary" != body.User.Summary || len(body.User.Experience) != 1 {
+			t.Errorf("Expected the profile as the resume selects it, but was %+v", body.User)
+		}
+
+		if body.Resume == nil || "SRE" != body.Resume.Name {
+			t.Errorf("Expected the owner to get the resume's definition")
+		}
+	}
+
+	getEvent.RequestContext.Authorizer = nil
+	body = &SuccessBody{}
+	if res, err := GetResume(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetResume: %s", err.Error())
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if body.Resume != nil || len(body.User.Email) > 0 {
+		t.Errorf("Expected an anonymous caller to only get the public view")
+	}
+
+	putEvent.Body = `{"experience": [{"company": "Nowhere", "job_title": "SRE"}]}`
+	if res, err := PutResume(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutResume: %s", err.Error())
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected a resume selecting a missing entry to be rejected, but status code was %d", res.StatusCode)
+	}
+
+	putEvent.RequestContext.Authorizer = authorizerFor("user2")
+	if res, err := PutResume(context.Background(), putEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutResume: %s", err.Error())
+	} else if http.StatusForbidden != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusForbidden, res.StatusCode)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorRouteNotFound = "route not found"
+)
+
+type HandlerFunc func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error)
+
+// Router dispatches on the API Gateway resource (e.g. "/user/{id}") and HTTP method of a request
+type Router struct {
+	routes     map[string]map[string]HandlerFunc
+	middleware []Middleware
+}
+
+func NewRouter() *Router {
+	return &Router{routes: make(map[string]map[string]HandlerFunc)}
+}
+
+func (r *Router) Handle(method string, resource string, handler HandlerFunc) {
+	if _, ok := r.routes[resource]; !ok {
+		r.routes[resource] = make(map[string]HandlerFunc)
+	}
+
+	r.routes[resource][method] = handler
+}
+
+// Use wraps every routed handler in the middleware. Middleware runs in the order it was added.
+func (r *Router) Use(middleware Middleware) {
+	r.middleware = append(r.middleware, middleware)
+}
+
+// Route calls the handler registered for the request's resource and method. Requests for a resource or method that
+// isn't registered are answered by the router, but still go through the middleware so CORS headers are added to the
+// error and browsers can read it.
+func (r *Router) Route(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	methods, found := r.routes[req.Resource]
+	allow := strings.Join(r.Methods(req.Resource), ", ")
+	handler, ok := methods[req.HTTPMethod]
+	if !found {
+		handler = notFound
+	} else if !ok && req.HTTPMethod == http.MethodOptions {
+		// CORS turns it into a preflight response
+		handler = options(allow)
+	} else if !ok {
+		handler = methodNotAllowed(allow)
+	}
+
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+
+	return handler(ctx, req, svc, logger)
+}
+
+// Methods are the methods registered for the resource, along with OPTIONS which every resource answers
+func (r *Router) Methods(resource string) []string {
+	methods := []string{http.MethodOptions}
+	for method := range r.routes[resource] {
+		if method != http.MethodOptions {
+			methods = append(methods, method)
+		}
+	}
+
+	sort.Strings(methods)
+	return methods
+}
+
+func options(allow string) HandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		resp := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusNoContent,
+			Headers:    map[string]string{"Allow": allow},
+		}
+		if len(req.RequestContext.RequestID) > 0 {
+			resp.Headers[RequestIdHeader] = req.RequestContext.RequestID
+		}
+
+		return resp, nil
+	}
+}
+
+func notFound(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	logger.Warn("No route for resource", zap.String("resource", req.Resource))
+	return apiResponse(req, http.StatusNotFound, ErrorBody{ErrorMsg: aws.String(ErrorRouteNotFound)}, logger)
+}
+
+func methodNotAllowed(allow string) HandlerFunc {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		resp, err := UnhandledMethod(req, logger)
+		if resp != nil {
+			resp.Headers["Allow"] = allow
+		}
+		return resp, err
+	}
+}
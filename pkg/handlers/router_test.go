@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+func TestRouter(t *testing.T) {
+	setupHandler(t)
+
+	called := false
+	router := NewRouter()
+	router.Handle("GET", "/user/{id}", func(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+		called = true
+		return apiResponse(req, http.StatusOK, SuccessBody{}, logger)
+	})
+
+	event := events.APIGatewayProxyRequest{Resource: "/user/{id}", HTTPMethod: "GET"}
+	if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to route request: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode || !called {
+		t.Errorf("Expected the registered handler to be called, but status code was %d", res.StatusCode)
+	}
+
+	event.HTTPMethod = "PATCH"
+	if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to route request: %s", err.Error())
+	} else if http.StatusMethodNotAllowed != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusMethodNotAllowed, res.StatusCode)
+	} else if "GET, OPTIONS" != res.Headers["Allow"] {
+		t.Errorf("Expected the allowed methods to be 'GET, OPTIONS', but was '%s'", res.Headers["Allow"])
+	}
+
+	event.HTTPMethod = "OPTIONS"
+	if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to route request: %s", err.Error())
+	} else if http.StatusNoContent != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusNoContent, res.StatusCode)
+	} else if "GET, OPTIONS" != res.Headers["Allow"] {
+		t.Errorf("Expected the allowed methods to be 'GET, OPTIONS', but was '%s'", res.Headers["Allow"])
+	}
+
+	event.Resource = "/unknown"
+	if res, err := router.Route(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to route request: %s", err.Error())
+	} else if http.StatusNotFound != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusNotFound, res.StatusCode)
+	}
+}
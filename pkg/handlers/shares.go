@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/share"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultShareExpiryDays = 30
+	MaxShareExpiryDays     = 365
+
+	ErrorInvalidShareExpiry = "expires_in_days must be between 1 and 365"
+	ErrorInvalidMaxViews    = "max_views must not be negative"
+	ErrorShareIdNotProvided = "shareId not provided"
+	ErrorTokenNotProvided   = "token not provided"
+)
+
+type CreateShareRequest struct {
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+	MaxViews      int `json:"max_views,omitempty"`
+}
+
+// ShareHandler serves the share link routes. Secret signs the tokens handed out in links and must be the same across
+// every instance of the function.
+type ShareHandler struct {
+	Secret []byte
+	Now    func() time.Time
+}
+
+func NewShareHandler(secret []byte) *ShareHandler {
+	return &ShareHandler{Secret: secret, Now: time.Now}
+}
+
+func (h *ShareHandler) CreateShare(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	shareReq := &CreateShareRequest{}
+	if len(req.Body) > 0 {
+		if err := decodeJSON(req, shareReq); err != nil {
+			logger.Error("Failed to unmarshal body into CreateShareRequest object", zap.Error(err), zap.String("body", req.Body))
+			return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+		}
+	}
+
+	if shareReq.ExpiresInDays == 0 {
+		shareReq.ExpiresInDays = DefaultShareExpiryDays
+	}
+
+	if shareReq.ExpiresInDays < 1 || shareReq.ExpiresInDays > MaxShareExpiryDays {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorInvalidShareExpiry), Field: aws.String("expires_in_days")}, logger)
+	}
+
+	if shareReq.MaxViews < 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorInvalidMaxViews), Field: aws.String("max_views")}, logger)
+	}
+
+	tenantId := tenantFromRequest(req)
+	if _, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: tenantId, UserId: userId}, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	shareId, err := share.NewShareId()
+	if err != nil {
+		logger.Error("Failed to generate share ID", zap.Error(err))
+		return apiResponse(req, http.StatusInternalServerError, newErrorBody(err), logger)
+	}
+
+	now := h.Now()
+	newShare := &models.Share{
+		TenantId:  tenantId,
+		ShareId:   shareId,
+		UserId:    userId,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.AddDate(0, 0, shareReq.ExpiresInDays).Unix(),
+		MaxViews:  shareReq.MaxViews,
+	}
+
+	token, err := share.Sign(share.Claims{ShareId: newShare.ShareId, UserId: newShare.UserId, ExpiresAt: newShare.ExpiresAt}, h.Secret)
+	if err != nil {
+		logger.Error("Failed to sign share token", zap.Error(err))
+		return apiResponse(req, http.StatusInternalServerError, newErrorBody(err), logger)
+	}
+
+	if err := models.PutShare(ctx, newShare, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusCreated, SuccessBody{Share: newShare, Token: aws.String(token)}, logger)
+}
+
+// GetShared returns the resume behind a share token without requiring authentication. The token signature and expiry
+// are checked before anything is read, and the view is only counted if the share is still live and its resume is
+// returned.
+func (h *ShareHandler) GetShared(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	token := req.PathParameters["token"]
+	if len(token) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorTokenNotProvided)}, logger)
+	}
+
+	now := h.Now()
+	claims, err := share.Verify(token, h.Secret, now)
+	if err != nil {
+		logger.Warn("Rejected share token", zap.Error(err))
+		return h.sharedResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	shareKey := &models.ShareKey{ShareId: claims.ShareId}
+	viewed, err := models.RecordShareView(ctx, shareKey, claims.UserId, now, svc, logger)
+	if err != nil {
+		return h.sharedResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: viewed.TenantId, UserId: viewed.UserId}, svc, logger)
+	if err != nil {
+		// The tenant is only known once the share is read, so the view is given back rather than checked for first
+		if undoErr := models.UndoShareView(ctx, shareKey, svc, logger); undoErr != nil {
+			logger.Error("Failed to give back share view", zap.Error(undoErr))
+		}
+		return h.sharedResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return h.sharedResponse(req, http.StatusOK, SuccessBody{User: models.Redact(user, models.AccessSharedLink)}, logger)
+}
+
+// sharedResponse keeps shared resumes out of any cache, otherwise a revoked or used up link could still be served
+func (h *ShareHandler) sharedResponse(req events.APIGatewayProxyRequest, status int, body interface{}, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	resp, err := apiResponse(req, status, body, logger)
+	if resp != nil {
+		resp.Headers["Cache-Control"] = "private, no-store"
+	}
+
+	return resp, err
+}
+
+func (h *ShareHandler) RevokeShare(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	if err := authorize(ctx, req, svc, userId, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	shareId := req.PathParameters["shareId"]
+	if len(shareId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorShareIdNotProvided)}, logger)
+	}
+
+	if err := models.RevokeShare(ctx, &models.ShareKey{TenantId: tenantFromRequest(req), ShareId: shareId}, userId, svc, logger); err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	return apiResponse(req, http.StatusAccepted, SuccessBody{}, logger)
+}
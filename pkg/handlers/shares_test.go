@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestShareLifecycle(t *testing.T) {
+	setupHandler(t)
+
+	now := time.Unix(1600000000, 0)
+	shares := &ShareHandler{Secret: []byte("secret"), Now: func() time.Time { return now }}
+
+	var stored *models.Share
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		stored = &models.Share{}
+		_ = dynamodbattribute.UnmarshalMap(input.Item, stored)
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	createEvent := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}/shares",
+		Path:       "/v1/user/user1/shares",
+		HTTPMethod: "POST",
+		PathParameters: map[string]string{
+			"id": "user1",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: authorizerFor("user1"),
+		},
+		Body: `{"expires_in_days": 7, "max_views": 2}`,
+	}
+	created := &SuccessBody{}
+	if res, err := shares.CreateShare(context.Background(), createEvent, svc, logger); err != nil {
+		t.Fatalf("Failed to get a response for CreateShare: %s", err.Error())
+	} else if http.StatusCreated != res.StatusCode {
+		t.Fatalf("Expected status code to be %d, but was %d: %s", http.StatusCreated, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), created); jsonErr != nil {
+		t.Fatalf("Failed to unmarshal response body: %s", jsonErr.Error())
+	}
+
+	if created.Token == nil || created.Share == nil {
+		t.Fatalf("Expected a token and share in the response")
+	}
+
+	if stored == nil || now.AddDate(0, 0, 7).Unix() != stored.ExpiresAt || 2 != stored.MaxViews {
+		t.Errorf("Expected share to expire in 7 days after 2 views, but stored %+v", stored)
+	}
+
+	getEvent := events.APIGatewayProxyRequest{
+		Resource:   "/shared/{token}",
+		Path:       "/v1/shared/" + *created.Token,
+		HTTPMethod: "GET",
+		Headers: map[string]string{
+			"Accept": "text/html",
+		},
+		PathParameters: map[string]string{
+			"token": *created.Token,
+		},
+	}
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		stored.Views++
+		attr, _ := dynamodbattribute.MarshalMap(stored)
+		return &dynamodb.UpdateItemOutput{Attributes: attr}, nil
+	}
+	if res, err := shares.GetShared(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetShared: %s", err.Error())
+	} else {
+		if http.StatusOK != res.StatusCode {
+			t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+		}
+
+		if MediaTypeHTML != res.Headers[contentType] {
+			t.Errorf("Expected shared resume to be rendered as HTML, but was '%s'", res.Headers[contentType])
+		}
+
+		if "private, no-store" != res.Headers["Cache-Control"] {
+			t.Errorf("Expected shared resume to not be cached")
+		}
+	}
+
+	// A view that can't return the resume isn't counted
+	getUser := mocks.GetItemMock
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		if "ADD #views :minus" == *input.UpdateExpression {
+			stored.Views--
+			return &dynamodb.UpdateItemOutput{}, nil
+		}
+		stored.Views++
+		attr, _ := dynamodbattribute.MarshalMap(stored)
+		return &dynamodb.UpdateItemOutput{Attributes: attr}, nil
+	}
+	if res, err := shares.GetShared(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetShared: %s", err.Error())
+	} else if http.StatusNotFound != res.StatusCode || 1 != stored.Views {
+		t.Errorf("Expected a missing user to be not found without using a view, but status code was %d after %d views", res.StatusCode, stored.Views)
+	}
+	mocks.GetItemMock = getUser
+
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	if res, err := shares.GetShared(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetShared: %s", err.Error())
+	} else if http.StatusGone != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusGone, res.StatusCode)
+	}
+
+	getEvent.PathParameters["token"] = *created.Token + "tampered"
+	if res, err := shares.GetShared(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetShared: %s", err.Error())
+	} else if http.StatusNotFound != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	now = now.AddDate(0, 0, 8)
+	getEvent.PathParameters["token"] = *created.Token
+	if res, err := shares.GetShared(context.Background(), getEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for GetShared: %s", err.Error())
+	} else if http.StatusGone != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusGone, res.StatusCode)
+	}
+
+	revokeEvent := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}/shares/{shareId}",
+		Path:       "/v1/user/user1/shares/" + created.Share.ShareId,
+		HTTPMethod: "DELETE",
+		PathParameters: map[string]string{
+			"id":      "user1",
+			"shareId": created.Share.ShareId,
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: authorizerFor("user1"),
+		},
+	}
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+	if res, err := shares.RevokeShare(context.Background(), revokeEvent, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for RevokeShare: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d", http.StatusAccepted, res.StatusCode)
+	}
+}
+
+func TestCreateShareValidation(t *testing.T) {
+	setupHandler(t)
+
+	shares := NewShareHandler([]byte("secret"))
+	event := events.APIGatewayProxyRequest{
+		Resource:   "/user/{id}/shares",
+		HTTPMethod: "POST",
+		PathParameters: map[string]string{
+			"id": "user1",
+		},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: authorizerFor("user1"),
+		},
+	}
+
+	for _, body := range []string{`{"expires_in_days": 400}`, `{"expires_in_days": -1}`, `{"max_views": -1}`, `not json`} {
+		event.Body = body
+		if res, err := shares.CreateShare(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for CreateShare: %s", err.Error())
+		} else if http.StatusBadRequest != res.StatusCode {
+			t.Errorf("Expected status code for body '%s' to be %d, but was %d", body, http.StatusBadRequest, res.StatusCode)
+		}
+	}
+
+	event.Body = ""
+	noSecret := NewShareHandler(nil)
+	if res, err := noSecret.CreateShare(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for CreateShare: %s", err.Error())
+	} else if http.StatusInternalServerError != res.StatusCode {
+		t.Errorf("Expected status code without a secret to be %d, but was %d", http.StatusInternalServerError, res.StatusCode)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/tailor"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorJobDescriptionNotProvided = "job_description not provided"
+)
+
+type TailorRequest struct {
+	JobDescription string `json:"job_description"`
+	PageBudget     int    `json:"page_budget,omitempty"`
+}
+
+// Tailor returns a variant of the user's resume ranked and trimmed for a job posting, along with how well they match.
+// Nothing is stored, and the caller only gets to tailor as much of the resume as they are allowed to see. It is a POST,
+// so like every other POST it needs a signed in user, and API keys can't call it.
+func Tailor(ctx context.Context, req events.APIGatewayProxyRequest, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*events.APIGatewayProxyResponse, error) {
+	userId := req.PathParameters["id"]
+	if len(userId) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorUserIdNotProvided)}, logger)
+	}
+
+	tailorReq := &TailorRequest{}
+	if err := decodeJSON(req, tailorReq); err != nil {
+		logger.Error("Failed to unmarshal body into TailorRequest object", zap.Error(err), zap.String("body", req.Body))
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	if len(tailorReq.JobDescription) == 0 {
+		return apiResponse(req, http.StatusBadRequest, ErrorBody{ErrorMsg: aws.String(ErrorJobDescriptionNotProvided), Field: aws.String("job_description")}, logger)
+	}
+
+	user, err := models.GetUserByKey(ctx, &models.UserKey{TenantId: tenantFromRequest(req), UserId: userId}, svc, logger)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), newErrorBody(err), logger)
+	}
+
+	result, err := tailor.Tailor(models.Redact(user, accessLevel(ctx, req, user, svc, logger)), tailorReq.JobDescription, tailorReq.PageBudget)
+	if err != nil {
+		return apiResponse(req, getErrorStatusCode(err), ErrorBody{ErrorMsg: aws.String(err.Error()), Field: aws.String("job_description")}, logger)
+	}
+
+	return apiResponse(req, http.StatusOK, SuccessBody{User: result.User, Tailoring: result}, logger)
+}
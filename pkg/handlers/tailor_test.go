@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func TestTailor(t *testing.T) {
+	setupHandler(t)
+	user.Experience = []models.Experience{{Company: "Co", JobTitle: "SRE", Responsibilities: []string{"Ran the book club", "Ran Kubernetes clusters"}}}
+	user.Skills = []models.Skill{{Name: "Excel"}, {Name: "Kubernetes"}}
+
+	attr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: attr}, nil
+	}
+
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}/tailor",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizerFor("user1")},
+		Body:           `{"job_description": "Platform engineer running Kubernetes in production"}`,
+	}
+	body := &SuccessBody{}
+	if res, err := Tailor(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for Tailor: %s", err.Error())
+	} else if http.StatusOK != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusOK, res.StatusCode, res.Body)
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else {
+		if "Ran Kubernetes clusters" != body.User.Experience[0].Responsibilities[0] || "Kubernetes" != body.User.Skills[0].Name {
+			t.Errorf("Expected the Kubernetes entries to be ranked first, but was %+v", body.User)
+		}
+
+		if body.Tailoring == nil || body.Tailoring.Score <= 0 {
+			t.Errorf("Expected a match score to be reported, but was %+v", body.Tailoring)
+		}
+
+		if user.Email != body.User.Email {
+			t.Errorf("Expected the owner to get the full profile")
+		}
+	}
+
+	event.RequestContext.Authorizer = nil
+	body = &SuccessBody{}
+	if res, err := Tailor(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for Tailor: %s", err.Error())
+	} else if jsonErr := json.Unmarshal([]byte(res.Body), body); jsonErr != nil {
+		t.Errorf("Failed to unmarshal response body: %s", jsonErr.Error())
+	} else if len(body.User.Email) > 0 {
+		t.Errorf("Expected an anonymous caller to only get the public view")
+	}
+
+	for _, reqBody := range []string{`{}`, `{"job_description": "the and of"}`} {
+		event.Body = reqBody
+		if res, err := Tailor(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for Tailor: %s", err.Error())
+		} else if http.StatusBadRequest != res.StatusCode {
+			t.Errorf("Expected status code to be %d for body %s, but was %d", http.StatusBadRequest, reqBody, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"github.com/bkimbrough88/resume-backend/pkg/auth"
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func tenantAuthorizerFor(tenantId string, userId string, roles ...string) map[string]interface{} {
+	principal := &auth.Principal{Subject: "auth0|" + userId, UserId: userId, TenantId: tenantId, Roles: roles, Method: auth.MethodJWT}
+	return principal.AuthorizerContext()
+}
+
+func TestCrossTenantReads(t *testing.T) {
+	setupHandler(t)
+
+	// user1's resume only exists in tenant1
+	user.TenantId = "tenant1"
+	attr, _ := dynamodbattribute.MarshalMap(user)
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		if pk := input.Key[models.PartitionKey]; pk != nil && "tenant1#user1" == *pk.S {
+			return &dynamodb.GetItemOutput{Item: attr}, nil
+		}
+		return &dynamodb.GetItemOutput{}, nil
+	}
+
+	tests := map[string]struct {
+		authorizer map[string]interface{}
+		status     int
+	}{
+		"same tenant":          {tenantAuthorizerFor("tenant1", "user2"), http.StatusOK},
+		"other tenant":         {tenantAuthorizerFor("tenant2", "user2"), http.StatusNotFound},
+		"same user id":         {tenantAuthorizerFor("tenant2", "user1"), http.StatusNotFound},
+		"other tenant's admin": {tenantAuthorizerFor("tenant2", "user2", auth.RoleAdmin), http.StatusNotFound},
+		"anonymous":            {nil, http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		event := events.APIGatewayProxyRequest{
+			Resource:       "/user/{id}",
+			HTTPMethod:     "GET",
+			PathParameters: map[string]string{"id": "user1"},
+			RequestContext: events.APIGatewayProxyRequestContext{Authorizer: test.authorizer},
+		}
+		if res, err := GetUser(context.Background(), event, svc, logger); err != nil {
+			t.Errorf("Failed to get a response for GetUser as %s: %s", name, err.Error())
+		} else if test.status != res.StatusCode {
+			t.Errorf("Expected GetUser status code as %s to be %d, but was %d", name, test.status, res.StatusCode)
+		}
+	}
+
+	// Writes land in the caller's tenant whatever the body says
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if "tenant2#user1" != *input.Item[models.PartitionKey].S {
+			t.Errorf("Expected user to be written to tenant2, but pk was '%s'", *input.Item[models.PartitionKey].S)
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	event := events.APIGatewayProxyRequest{
+		Resource:       "/user/{id}",
+		HTTPMethod:     "POST",
+		PathParameters: map[string]string{"id": "user1"},
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: tenantAuthorizerFor("tenant2", "user1")},
+		Body:           `{"user_id": "user1", "email": "user1@domain.com"}`,
+	}
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if http.StatusAccepted != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusAccepted, res.StatusCode, res.Body)
+	}
+
+	// The tenant can't be sent at all, rather than being silently dropped
+	event.Body = `{"user_id": "user1", "email": "user1@domain.com", "tenant_id": "tenant1"}`
+	if res, err := PutUser(context.Background(), event, svc, logger); err != nil {
+		t.Errorf("Failed to get a response for PutUser: %s", err.Error())
+	} else if http.StatusBadRequest != res.StatusCode {
+		t.Errorf("Expected status code to be %d, but was %d: %s", http.StatusBadRequest, res.StatusCode, res.Body)
+	}
+}
@@ -0,0 +1,251 @@
+package linkedin
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+const (
+	ErrorArchiveTooLarge = "archive is too large"
+	ErrorNoKnownFiles    = "archive does not contain any LinkedIn export files"
+
+	certificationsFile = "certifications.csv"
+	educationFile      = "education.csv"
+	emailsFile         = "email addresses.csv"
+	positionsFile      = "positions.csv"
+	profileFile        = "profile.csv"
+	skillsFile         = "skills.csv"
+)
+
+var ErrArchiveTooLarge = errors.New(ErrorArchiveTooLarge)
+
+// Limits on what is decompressed from an archive, so a small zip can't expand to more than the function has memory for
+var (
+	MaxEntries      = 1000
+	MaxEntryBytes   = int64(10 << 20)
+	MaxArchiveBytes = int64(32 << 20)
+)
+
+type fileParser struct {
+	headerColumn string
+	parse        func(rows []map[string]string, user *models.User)
+}
+
+var parsers = map[string]fileParser{
+	certificationsFile: {headerColumn: "Name", parse: parseCertifications},
+	educationFile:      {headerColumn: "School Name", parse: parseEducation},
+	emailsFile:         {headerColumn: "Email Address", parse: parseEmails},
+	positionsFile:      {headerColumn: "Company Name", parse: parsePositions},
+	profileFile:        {headerColumn: "First Name", parse: parseProfile},
+	skillsFile:         {headerColumn: "Name", parse: parseSkills},
+}
+
+// Parse builds a user from the zip archive produced by LinkedIn's "download your data" export. Only the files that
+// map onto a resume are read, and any of them may be missing from the archive.
+func Parse(archive []byte) (*models.User, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reader.File) > MaxEntries {
+		return nil, fmt.Errorf("%w, it has %d files and the limit is %d", ErrArchiveTooLarge, len(reader.File), MaxEntries)
+	}
+
+	user := &models.User{}
+	found := false
+	remaining := MaxArchiveBytes
+	for _, file := range reader.File {
+		parser, ok := parsers[strings.ToLower(path.Base(file.Name))]
+		if !ok {
+			continue
+		}
+
+		limit := MaxEntryBytes
+		if remaining < limit {
+			limit = remaining
+		}
+
+		rows, size, err := readCSV(file, parser.headerColumn, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		remaining -= size
+		parser.parse(rows, user)
+		found = true
+	}
+
+	if !found {
+		return nil, errors.New(ErrorNoKnownFiles)
+	}
+
+	return user, nil
+}
+
+// readCSV returns each row keyed by its header. Some exports start with a few lines of notes before the header, so
+// anything before the first row containing headerColumn is skipped. It also returns how many bytes were decompressed,
+// which may be no more than limit whatever the archive claims the file's size is.
+func readCSV(file *zip.File, headerColumn string, limit int64) ([]map[string]string, int64, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(data)) > limit {
+		return nil, 0, fmt.Errorf("%w, the file decompresses to more than %d bytes", ErrArchiveTooLarge, limit)
+	}
+	size := int64(len(data))
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var header []string
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+
+		if header == nil {
+			for _, column := range record {
+				if strings.TrimSpace(column) == headerColumn {
+					header = record
+					break
+				}
+			}
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(name)] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, size, nil
+}
+
+func parseProfile(rows []map[string]string, user *models.User) {
+	if len(rows) == 0 {
+		return
+	}
+
+	user.GivenName = rows[0]["First Name"]
+	user.SurName = rows[0]["Last Name"]
+	user.Summary = rows[0]["Summary"]
+	user.Location = rows[0]["Geo Location"]
+}
+
+func parseEmails(rows []map[string]string, user *models.User) {
+	for _, row := range rows {
+		if len(user.Email) == 0 || strings.EqualFold(row["Primary"], "yes") {
+			user.Email = row["Email Address"]
+		}
+	}
+}
+
+func parsePositions(rows []map[string]string, user *models.User) {
+	for _, row := range rows {
+		exp := models.Experience{
+			Company:          row["Company Name"],
+			JobTitle:         row["Title"],
+			Responsibilities: splitDescription(row["Description"]),
+		}
+		exp.StartDate = parseDate(row["Started On"])
+		if end := parseDate(row["Finished On"]); !end.IsZero() {
+			exp.EndDate = &end
+		}
+
+		if len(exp.Company) > 0 || len(exp.JobTitle) > 0 {
+			user.Experience = append(user.Experience, exp)
+		}
+	}
+}
+
+// parseEducation splits LinkedIn's single "Degree Name" column, which is usually written as "Degree, Major"
+func parseEducation(rows []map[string]string, user *models.User) {
+	for _, row := range rows {
+		degree := models.Degree{School: row["School Name"]}
+		parts := strings.SplitN(row["Degree Name"], ",", 2)
+		degree.Degree = strings.TrimSpace(parts[0])
+		if len(parts) > 1 {
+			degree.Major = strings.TrimSpace(parts[1])
+		}
+		degree.StartYear = parseDate(row["Start Date"]).Year
+		degree.EndYear = parseDate(row["End Date"]).Year
+
+		if len(degree.School) > 0 {
+			user.Degrees = append(user.Degrees, degree)
+		}
+	}
+}
+
+func parseSkills(rows []map[string]string, user *models.User) {
+	for _, row := range rows {
+		if name := row["Name"]; len(name) > 0 {
+			user.Skills = append(user.Skills, models.Skill{Name: name})
+		}
+	}
+}
+
+func parseCertifications(rows []map[string]string, user *models.User) {
+	for _, row := range rows {
+		cert := models.Certification{
+			Name:         row["Name"],
+			BadgeLink:    row["Url"],
+			DateAchieved: parseDate(row["Started On"]),
+		}
+		if expires := parseDate(row["Finished On"]); !expires.IsZero() {
+			cert.DateExpires = &expires
+		}
+
+		if len(cert.Name) > 0 {
+			user.Certifications = append(user.Certifications, cert)
+		}
+	}
+}
+
+// parseDate handles LinkedIn's "Jan 2020" and "2020" date formats. Anything else is dropped rather than failing the
+// whole import.
+func parseDate(value string) models.Date {
+	date, err := models.ParseDate(value)
+	if err != nil {
+		return models.Date{}
+	}
+
+	return date
+}
+
+func splitDescription(description string) []string {
+	var responsibilities []string
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if len(line) > 0 {
+			responsibilities = append(responsibilities, line)
+		}
+	}
+
+	return responsibilities
+}
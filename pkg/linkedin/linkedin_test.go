@@ -0,0 +1,131 @@
+package linkedin
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func buildArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s to archive: %s", name, err.Error())
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s to archive: %s", name, err.Error())
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %s", err.Error())
+	}
+
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	archive := buildArchive(t, map[string]string{
+		"Profile.csv": "First Name,Last Name,Maiden Name,Address,Birth Date,Headline,Summary,Industry,Zip Code,Geo Location\n" +
+			"John,Doe,,,,SRE,My summary,Software,,\"Place, State\"\n",
+		"Email Addresses.csv": "Email Address,Confirmed,Primary,Updated On\n" +
+			"old@domain.com,Yes,No,\n" +
+			"user@domain.com,Yes,Yes,\n",
+		"Positions.csv": "Company Name,Title,Description,Location,Started On,Finished On\n" +
+			"Co,SRE,\"- Kept things running\n- Wrote Go\",Place,May 2020,Jun 2021\n" +
+			"Other Co,Dev,,Place,2019,\n",
+		"Education.csv": "School Name,Start Date,End Date,Notes,Degree Name,Activities\n" +
+			"University,2017,2021,,\"BS, Computer Science\",\n",
+		"Skills.csv": "Name\nGo\nTerraform\n",
+		"nested/Certifications.csv": "Notes:\n\"Some note about this file\"\n\n" +
+			"Name,Url,Authority,Started On,Finished On,License Number\n" +
+			"Some Cert,https://example.com,Org,Oct 2019,Oct 2022,123\n",
+		"Connections.csv": "First Name,Last Name\nJane,Doe\n",
+	})
+
+	user, err := Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse archive: %s", err.Error())
+	}
+
+	if "John" != user.GivenName || "Doe" != user.SurName {
+		t.Errorf("Expected name to be 'John Doe', but was '%s %s'", user.GivenName, user.SurName)
+	}
+
+	if "user@domain.com" != user.Email {
+		t.Errorf("Expected primary email to be used, but was '%s'", user.Email)
+	}
+
+	if len(user.Experience) != 2 {
+		t.Fatalf("Expected 2 positions, but got %d", len(user.Experience))
+	} else {
+		exp := user.Experience[0]
+		if "2020-05" != exp.StartDate.String() || exp.EndDate == nil || "2021-06" != exp.EndDate.String() {
+			t.Errorf("Expected position to run May 2020 - June 2021, but was %+v", exp)
+		}
+
+		if len(exp.Responsibilities) != 2 || "Kept things running" != exp.Responsibilities[0] {
+			t.Errorf("Expected description to be split into responsibilities, but was %v", exp.Responsibilities)
+		}
+
+		if "2019" != user.Experience[1].StartDate.String() {
+			t.Errorf("Expected year only start date, but was %+v", user.Experience[1])
+		}
+	}
+
+	if len(user.Degrees) != 1 || "BS" != user.Degrees[0].Degree || "Computer Science" != user.Degrees[0].Major {
+		t.Errorf("Expected degree name to be split into degree and major, but was %+v", user.Degrees)
+	}
+
+	if len(user.Skills) != 2 {
+		t.Errorf("Expected 2 skills, but got %d", len(user.Skills))
+	}
+
+	if len(user.Certifications) != 1 || "2019-10" != user.Certifications[0].DateAchieved.String() {
+		t.Errorf("Expected the certification after the notes preamble, but was %+v", user.Certifications)
+	}
+
+	if _, err := Parse(buildArchive(t, map[string]string{"Connections.csv": "First Name\nJane\n"})); err == nil {
+		t.Errorf("Expected an archive without export files to fail")
+	} else if ErrorNoKnownFiles != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorNoKnownFiles, err.Error())
+	}
+
+	if _, err := Parse([]byte("not a zip")); err == nil {
+		t.Errorf("Expected a non-zip body to fail")
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	defer func(entries int, entryBytes int64, archiveBytes int64) {
+		MaxEntries, MaxEntryBytes, MaxArchiveBytes = entries, entryBytes, archiveBytes
+	}(MaxEntries, MaxEntryBytes, MaxArchiveBytes)
+	MaxEntries, MaxEntryBytes, MaxArchiveBytes = 3, 1024, 1536
+
+	// Compresses to almost nothing, but is well over the limit once decompressed
+	bomb := buildArchive(t, map[string]string{"Skills.csv": "Name\n" + strings.Repeat("Go\n", 1024)})
+	if _, err := Parse(bomb); !errors.Is(err, ErrArchiveTooLarge) {
+		t.Errorf("Expected an entry over the limit to fail with '%s', but was %v", ErrorArchiveTooLarge, err)
+	}
+
+	total := buildArchive(t, map[string]string{
+		"Skills.csv":    "Name\n" + strings.Repeat("Go\n", 300),
+		"Positions.csv": "Company Name\n" + strings.Repeat("Co\n", 300),
+	})
+	if _, err := Parse(total); !errors.Is(err, ErrArchiveTooLarge) {
+		t.Errorf("Expected entries over the total limit to fail with '%s', but was %v", ErrorArchiveTooLarge, err)
+	}
+
+	many := buildArchive(t, map[string]string{"Skills.csv": "Name\nGo\n", "a.csv": "", "b.csv": "", "c.csv": ""})
+	if _, err := Parse(many); !errors.Is(err, ErrArchiveTooLarge) {
+		t.Errorf("Expected too many entries to fail with '%s', but was %v", ErrorArchiveTooLarge, err)
+	}
+
+	if _, err := Parse(buildArchive(t, map[string]string{"Skills.csv": "Name\nGo\n"})); err != nil {
+		t.Errorf("Expected an archive under the limits to parse, but was %s", err.Error())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
@@ -14,7 +15,11 @@ var (
 	DeleteItemMock func(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
 	GetItemMock    func(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
 	PutItemMock    func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	QueryMock      func(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	ScanMock       func(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
 	UpdateItemMock func(*dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
+
+	TransactWriteItemsMock func(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
 )
 
 type DynamoServiceMock struct{}
@@ -99,8 +104,11 @@ func (d DynamoServiceMock) DeleteBackupRequest(*dynamodb.DeleteBackupInput) (*re
 func (d DynamoServiceMock) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 	return DeleteItemMock(input)
 }
-func (d DynamoServiceMock) DeleteItemWithContext(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.DeleteItem(input)
 }
 func (d DynamoServiceMock) DeleteItemRequest(*dynamodb.DeleteItemInput) (*request.Request, *dynamodb.DeleteItemOutput) {
 	return nil, nil
@@ -289,8 +297,11 @@ func (d DynamoServiceMock) ExportTableToPointInTimeRequest(*dynamodb.ExportTable
 func (d DynamoServiceMock) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 	return GetItemMock(input)
 }
-func (d DynamoServiceMock) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.GetItem(input)
 }
 func (d DynamoServiceMock) GetItemRequest(*dynamodb.GetItemInput) (*request.Request, *dynamodb.GetItemOutput) {
 	return nil, nil
@@ -380,18 +391,24 @@ func (d DynamoServiceMock) ListTagsOfResourceRequest(*dynamodb.ListTagsOfResourc
 func (d DynamoServiceMock) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
 	return PutItemMock(input)
 }
-func (d DynamoServiceMock) PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.PutItem(input)
 }
 func (d DynamoServiceMock) PutItemRequest(*dynamodb.PutItemInput) (*request.Request, *dynamodb.PutItemOutput) {
 	return nil, nil
 }
 
-func (d DynamoServiceMock) Query(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return QueryMock(input)
 }
-func (d DynamoServiceMock) QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.Query(input)
 }
 func (d DynamoServiceMock) QueryRequest(*dynamodb.QueryInput) (*request.Request, *dynamodb.QueryOutput) {
 	return nil, nil
@@ -424,11 +441,14 @@ func (d DynamoServiceMock) RestoreTableToPointInTimeRequest(*dynamodb.RestoreTab
 	return nil, nil
 }
 
-func (d DynamoServiceMock) Scan(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return ScanMock(input)
 }
-func (d DynamoServiceMock) ScanWithContext(aws.Context, *dynamodb.ScanInput, ...request.Option) (*dynamodb.ScanOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.Scan(input)
 }
 func (d DynamoServiceMock) ScanRequest(*dynamodb.ScanInput) (*request.Request, *dynamodb.ScanOutput) {
 	return nil, nil
@@ -461,11 +481,14 @@ func (d DynamoServiceMock) TransactGetItemsRequest(*dynamodb.TransactGetItemsInp
 	return nil, nil
 }
 
-func (d DynamoServiceMock) TransactWriteItems(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return TransactWriteItemsMock(input)
 }
-func (d DynamoServiceMock) TransactWriteItemsWithContext(aws.Context, *dynamodb.TransactWriteItemsInput, ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.TransactWriteItems(input)
 }
 func (d DynamoServiceMock) TransactWriteItemsRequest(*dynamodb.TransactWriteItemsInput) (*request.Request, *dynamodb.TransactWriteItemsOutput) {
 	return nil, nil
@@ -524,8 +547,11 @@ func (d DynamoServiceMock) UpdateGlobalTableSettingsRequest(*dynamodb.UpdateGlob
 func (d DynamoServiceMock) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
 	return UpdateItemMock(input)
 }
-func (d DynamoServiceMock) UpdateItemWithContext(aws.Context, *dynamodb.UpdateItemInput, ...request.Option) (*dynamodb.UpdateItemOutput, error) {
-	return nil, errors.New("unimplemented")
+func (d DynamoServiceMock) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return d.UpdateItem(input)
 }
 func (d DynamoServiceMock) UpdateItemRequest(*dynamodb.UpdateItemInput) (*request.Request, *dynamodb.UpdateItemOutput) {
 	return nil, nil
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorInvalidApiKeyId   = "invalid key_id"
+	ErrorInvalidSecretHash = "invalid secret_hash"
+	ErrorScopesNotProvided = "at least one scope is required"
+)
+
+var ApiKeysTable = "resume_api_key"
+
+// ApiKey grants an integrator read-only access on behalf of the user who issued it. Only a hash of the key's secret is
+// stored, and it is never serialized into API responses. Like shares, keys are looked up before the tenant is known, so
+// the tenant is recorded on the key rather than in its table key.
+type ApiKey struct {
+	TenantId   string   `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	KeyId      string   `json:"key_id" yaml:"key_id" xml:"key_id"`
+	UserId     string   `json:"user_id" yaml:"user_id" xml:"user_id"`
+	Name       string   `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	Scopes     []string `json:"scopes" yaml:"scopes" xml:"scopes>scope"`
+	SecretHash string   `json:"-" yaml:"-" xml:"-" dynamodbav:"secret_hash"`
+	CreatedAt  int64    `json:"created_at" yaml:"created_at" xml:"created_at"`
+	RotatedAt  int64    `json:"rotated_at,omitempty" yaml:"rotated_at,omitempty" xml:"rotated_at,omitempty"`
+	Revoked    bool     `json:"revoked" yaml:"revoked" xml:"revoked"`
+}
+
+type ApiKeyKey struct {
+	TenantId string `json:"-"`
+	KeyId    string `json:"key_id"`
+}
+
+func PutApiKey(ctx context.Context, key *ApiKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if err := validateTenantId(key.TenantId); err != nil {
+		logger.Error("TenantId is invalid", zap.String("tenant_id", key.TenantId))
+		return err
+	}
+
+	if len(key.KeyId) == 0 {
+		logger.Error("KeyId is empty")
+		return &FieldError{Field: "key_id", Err: ErrInvalidApiKeyId}
+	}
+
+	if len(key.UserId) == 0 {
+		logger.Error("UserId is empty")
+		return &FieldError{Field: "user_id", Err: ErrInvalidUserId}
+	}
+
+	if len(key.Scopes) == 0 {
+		logger.Error("Scopes are empty")
+		return &FieldError{Field: "scopes", Err: ErrScopesNotProvided}
+	}
+
+	if len(key.SecretHash) == 0 {
+		logger.Error("SecretHash is empty")
+		return &FieldError{Field: "secret_hash", Err: ErrInvalidSecretHash}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		logger.Error("Failed to construct input for create API key", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(ApiKeysTable),
+		ConditionExpression: aws.String("attribute_not_exists(key_id)"),
+	})
+	if err != nil {
+		logger.Error("Failed to insert new API key into database", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully inserted new API key into database", zap.String("key_id", key.KeyId), zap.String("user_id", key.UserId))
+	return nil
+}
+
+func GetApiKey(ctx context.Context, key *ApiKeyKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*ApiKey, error) {
+	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		logger.Error("Failed to get input to get API key", zap.Error(err))
+		return nil, err
+	}
+
+	result, err := svc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		Key:       keyAttr,
+		TableName: aws.String(ApiKeysTable),
+	})
+	if err != nil {
+		logger.Error("Failed to get API key", zap.Error(err), zap.String("key_id", key.KeyId))
+		return nil, err
+	}
+
+	if result.Item == nil {
+		logger.Warn("No API key found", zap.String("key_id", key.KeyId))
+		return nil, ErrNoResultsFound
+	}
+
+	apiKey := &ApiKey{}
+	if err := dynamodbattribute.UnmarshalMap(result.Item, apiKey); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes to ApiKey object", zap.Error(err))
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// RotateApiKey replaces the key's secret hash as long as the key belongs to userId in the key's tenant and has not been
+// revoked, otherwise it is treated as not found
+func RotateApiKey(ctx context.Context, key *ApiKeyKey, userId string, secretHash string, now time.Time, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*ApiKey, error) {
+	if len(secretHash) == 0 {
+		logger.Error("SecretHash is empty")
+		return nil, &FieldError{Field: "secret_hash", Err: ErrInvalidSecretHash}
+	}
+
+	input, err := getApiKeyUpdateInput(key, userId, "SET secret_hash = :hash, rotated_at = :now", map[string]*dynamodb.AttributeValue{
+		":hash": {S: aws.String(secretHash)},
+		":now":  {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+	})
+	if err != nil {
+		logger.Error("Failed to get input to rotate API key", zap.Error(err))
+		return nil, err
+	}
+
+	result, err := svc.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			logger.Warn("No active API key found for user", zap.String("key_id", key.KeyId), zap.String("user_id", userId))
+			return nil, ErrNoResultsFound
+		}
+
+		logger.Error("Failed to rotate API key", zap.Error(err), zap.String("key_id", key.KeyId))
+		return nil, err
+	}
+
+	apiKey := &ApiKey{}
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, apiKey); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes to ApiKey object", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Rotated API key", zap.String("key_id", key.KeyId), zap.String("user_id", userId))
+	return apiKey, nil
+}
+
+// RevokeApiKey marks the key as revoked as long as it belongs to userId in the key's tenant, otherwise it is treated as
+// not found
+func RevokeApiKey(ctx context.Context, key *ApiKeyKey, userId string, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	input, err := getApiKeyUpdateInput(key, userId, "SET #revoked = :true", map[string]*dynamodb.AttributeValue{
+		":true": {BOOL: aws.Bool(true)},
+	})
+	if err != nil {
+		logger.Error("Failed to get input to revoke API key", zap.Error(err))
+		return err
+	}
+
+	if _, err := svc.UpdateItemWithContext(ctx, input); err != nil {
+		if isConditionalCheckFailed(err) {
+			logger.Warn("No active API key found for user", zap.String("key_id", key.KeyId), zap.String("user_id", userId))
+			return ErrNoResultsFound
+		}
+
+		logger.Error("Failed to revoke API key", zap.Error(err), zap.String("key_id", key.KeyId))
+		return err
+	}
+
+	logger.Info("Revoked API key", zap.String("key_id", key.KeyId), zap.String("user_id", userId))
+	return nil
+}
+
+func getApiKeyUpdateInput(key *ApiKeyKey, userId string, update string, values map[string]*dynamodb.AttributeValue) (*dynamodb.UpdateItemInput, error) {
+	if err := validateTenantId(key.TenantId); err != nil {
+		return nil, err
+	}
+
+	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	values[":false"] = &dynamodb.AttributeValue{BOOL: aws.Bool(false)}
+	values[":tenant_id"] = &dynamodb.AttributeValue{S: aws.String(key.TenantId)}
+	values[":user_id"] = &dynamodb.AttributeValue{S: aws.String(userId)}
+
+	return &dynamodb.UpdateItemInput{
+		Key:                 keyAttr,
+		TableName:           aws.String(ApiKeysTable),
+		UpdateExpression:    aws.String(update),
+		ConditionExpression: aws.String("tenant_id = :tenant_id AND user_id = :user_id AND #revoked = :false"),
+		ExpressionAttributeNames: map[string]*string{
+			"#revoked": aws.String("revoked"),
+		},
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+// BackfillTenantKeys copies every item of a table from before tenants, keyed on the bare idAttribute, into its tenant
+// scoped replacement with pk = "default#<id>". Items are copied over whatever the replacement already has, so it can be
+// run again to pick up writes made to the old table since the last copy. It returns how many items were copied.
+func BackfillTenantKeys(ctx context.Context, from string, to string, idAttribute string, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (int, error) {
+	input := &dynamodb.ScanInput{TableName: aws.String(from)}
+	copied := 0
+	for {
+		result, err := svc.ScanWithContext(ctx, input)
+		if err != nil {
+			logger.Error("Failed to scan table to backfill", zap.Error(err), zap.String("table", from))
+			return copied, err
+		}
+
+		for _, item := range result.Items {
+			id, ok := item[idAttribute]
+			if !ok || id.S == nil {
+				return copied, fmt.Errorf("item in %s has no %s to key it on", from, idAttribute)
+			}
+
+			if err := tenantItem(item, DefaultTenant, *id.S); err != nil {
+				return copied, err
+			}
+			if _, ok := item["tenant_id"]; !ok {
+				item["tenant_id"] = &dynamodb.AttributeValue{S: aws.String(DefaultTenant)}
+			}
+
+			if _, err := svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{Item: item, TableName: aws.String(to)}); err != nil {
+				logger.Error("Failed to copy item into tenant scoped table", zap.Error(err), zap.String("table", to), zap.String(idAttribute, *id.S))
+				return copied, err
+			}
+			copied++
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			logger.Info("Backfilled tenant scoped table", zap.String("from", from), zap.String("to", to), zap.Int("items", copied))
+			return copied, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
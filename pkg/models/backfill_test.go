@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+func TestBackfillTenantKeys(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	pages := []*dynamodb.ScanOutput{
+		{
+			Items: []map[string]*dynamodb.AttributeValue{
+				{"user_id": {S: aws.String("user1")}, "email": {S: aws.String("user1@domain.com")}},
+			},
+			LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"user_id": {S: aws.String("user1")}},
+		},
+		{
+			Items: []map[string]*dynamodb.AttributeValue{
+				{"user_id": {S: aws.String("user2")}},
+			},
+		},
+	}
+	mocks.ScanMock = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		if "resume_user" != *input.TableName {
+			t.Errorf("Expected to scan 'resume_user', but scanned '%s'", *input.TableName)
+		}
+		if input.ExclusiveStartKey != nil {
+			return pages[1], nil
+		}
+		return pages[0], nil
+	}
+
+	copied := make(map[string]map[string]*dynamodb.AttributeValue)
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if "resume_tenant_user" != *input.TableName {
+			t.Errorf("Expected to copy into 'resume_tenant_user', but copied into '%s'", *input.TableName)
+		}
+		copied[*input.Item[PartitionKey].S] = input.Item
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	if count, err := BackfillTenantKeys(context.Background(), "resume_user", "resume_tenant_user", "user_id", svc, logger); err != nil {
+		t.Fatalf("Failed to backfill: %s", err.Error())
+	} else if count != 2 || len(copied) != 2 {
+		t.Errorf("Expected both pages to be copied, but copied %d items", count)
+	}
+
+	if item, ok := copied["default#user1"]; !ok {
+		t.Errorf("Expected user1 to be keyed in the default tenant, but copied %v", copied)
+	} else if "user1@domain.com" != *item["email"].S || DefaultTenant != *item["tenant_id"].S {
+		t.Errorf("Expected the rest of the item to be kept and the tenant set, but was %v", item)
+	}
+
+	mocks.ScanMock = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		return &dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{{"email": {S: aws.String("x")}}}}, nil
+	}
+	if _, err := BackfillTenantKeys(context.Background(), "resume_user", "resume_tenant_user", "user_id", svc, logger); err == nil {
+		t.Errorf("Expected an item without an id to be an error")
+	}
+}
@@ -1,12 +1,13 @@
 package models
 
+// Certification is a credential the user holds. DateExpires is nil for one that doesn't expire.
 type Certification struct {
-	Name         string `json:"name"`
-	DateAchieved string `json:"date_achieved"`
-	BadgeLink    string `json:"badge_link,omitempty"`
-	DateExpires  string `json:"date_expires,omitempty"`
+	Name         string `json:"name" yaml:"name" xml:"name"`
+	DateAchieved Date   `json:"date_achieved" yaml:"date_achieved" xml:"date_achieved"`
+	BadgeLink    string `json:"badge_link,omitempty" yaml:"badge_link,omitempty" xml:"badge_link,omitempty"`
+	DateExpires  *Date  `json:"date_expires,omitempty" yaml:"date_expires,omitempty" xml:"date_expires,omitempty"`
 }
 
 type CertificationKey struct {
-	CertificationName string `json:"certification_name"`
+	CertificationName string `json:"certification_name" yaml:"certification_name" xml:"certification_name"`
 }
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ErrorEndBeforeStart = "end date is before start date"
+	ErrorInvalidDate    = "invalid date, expected YYYY, YYYY-MM or YYYY-MM-DD"
+)
+
+// Date is a calendar date known to the year, month or day. It is written as ISO 8601 at the same precision, e.g. 2019,
+// 2019-10 or 2019-10-28, and the zero Date is written as an empty string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// dateLayouts are tried in order. The first three are the canonical formats, the rest are what resumes were written
+// with before dates were typed.
+var dateLayouts = []struct {
+	layout    string
+	precision int
+}{
+	{"2006", 1},
+	{"2006-01", 2},
+	{"2006-01-02", 3},
+	{"01-02-2006", 3},
+	{"1-2-2006", 3},
+	{"01/02/2006", 3},
+	{"1/2/2006", 3},
+	{"01/2006", 2},
+	{"1/2006", 2},
+	{"Jan 2006", 2},
+	{"January 2006", 2},
+	{"Jan 2, 2006", 3},
+	{"January 2, 2006", 3},
+	{"2 Jan 2006", 3},
+	{"2 January 2006", 3},
+}
+
+// ParseDate reads a date in any of the canonical or legacy formats. An empty string is the zero Date.
+func ParseDate(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return Date{}, nil
+	}
+
+	for _, format := range dateLayouts {
+		t, err := time.Parse(format.layout, value)
+		if err != nil {
+			continue
+		}
+
+		date := Date{Year: t.Year()}
+		if format.precision > 1 {
+			date.Month = t.Month()
+		}
+		if format.precision > 2 {
+			date.Day = t.Day()
+		}
+		return date, nil
+	}
+
+	return Date{}, ErrInvalidDate
+}
+
+// NewMonthDate builds a date from the separate month name and year that experience used to be stored as
+func NewMonthDate(month string, year int) (Date, error) {
+	if year == 0 {
+		return Date{}, nil
+	}
+
+	if len(month) == 0 {
+		return Date{Year: year}, nil
+	}
+
+	return ParseDate(fmt.Sprintf("%s %d", month, year))
+}
+
+func (d Date) IsZero() bool {
+	return d.Year == 0
+}
+
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Month == 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Day == 0:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	default:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+}
+
+// Compare orders dates at the precision both of them are known to, so 2019 is neither before nor after 2019-10
+func (d Date) Compare(other Date) int {
+	pairs := [][2]int{{d.Year, other.Year}, {int(d.Month), int(other.Month)}, {d.Day, other.Day}}
+	for _, pair := range pairs {
+		if pair[0] == 0 || pair[1] == 0 {
+			return 0
+		}
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// Time is the first day the date could refer to
+func (d Date) Time() time.Time {
+	month, day := d.Month, d.Day
+	if month == 0 {
+		month = time.January
+	}
+	if day == 0 {
+		day = 1
+	}
+
+	return time.Date(d.Year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	date, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON also accepts a bare year written as a number
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var year int
+	if err := json.Unmarshal(data, &year); err == nil {
+		*d = Date{Year: year}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ErrInvalidDate
+	}
+
+	return d.UnmarshalText([]byte(value))
+}
+
+func (d Date) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Date) UnmarshalYAML(node *yaml.Node) error {
+	return d.UnmarshalText([]byte(node.Value))
+}
+
+func (d Date) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	if d.IsZero() {
+		av.NULL = aws.Bool(true)
+		return nil
+	}
+
+	av.S = aws.String(d.String())
+	return nil
+}
+
+func (d *Date) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	switch {
+	case av.S != nil:
+		return d.UnmarshalText([]byte(*av.S))
+	case av.N != nil:
+		year, err := strconv.Atoi(*av.N)
+		if err != nil {
+			return ErrInvalidDate
+		}
+		*d = Date{Year: year}
+	default:
+		*d = Date{}
+	}
+
+	return nil
+}
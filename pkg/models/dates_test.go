@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func TestParseDate(t *testing.T) {
+	cases := map[string]string{
+		"2019":             "2019",
+		"2019-10":          "2019-10",
+		"2019-10-28":       "2019-10-28",
+		"10-28-2019":       "2019-10-28",
+		"10/28/2019":       "2019-10-28",
+		"Oct 2019":         "2019-10",
+		"October 2019":     "2019-10",
+		"October 28, 2019": "2019-10-28",
+		" 2019-10 ":        "2019-10",
+		"":                 "",
+	}
+	for value, expected := range cases {
+		if date, err := ParseDate(value); err != nil {
+			t.Errorf("Failed to parse '%s': %s", value, err.Error())
+		} else if expected != date.String() {
+			t.Errorf("Expected '%s' to parse as '%s', but was '%s'", value, expected, date.String())
+		}
+	}
+
+	for _, value := range []string{"sometime", "2019-13", "28-10-2019"} {
+		if _, err := ParseDate(value); err == nil || ErrorInvalidDate != err.Error() {
+			t.Errorf("Expected '%s' to fail with '%s', but was %v", value, ErrorInvalidDate, err)
+		}
+	}
+}
+
+func TestDateCompare(t *testing.T) {
+	year := Date{Year: 2019}
+	october := Date{Year: 2019, Month: time.October}
+	if year.Before(october) || october.Before(year) {
+		t.Errorf("Expected dates to compare equal at the precision both are known to")
+	}
+
+	if !october.Before(Date{Year: 2019, Month: time.November, Day: 1}) || !year.Before(Date{Year: 2020}) {
+		t.Errorf("Expected earlier dates to be before later ones")
+	}
+}
+
+func TestDateEncoding(t *testing.T) {
+	cert := Certification{Name: "Cert", DateAchieved: Date{Year: 2019, Month: time.October, Day: 28}}
+	if data, err := json.Marshal(cert); err != nil {
+		t.Errorf("Failed to marshal certification: %s", err.Error())
+	} else if `{"name":"Cert","date_achieved":"2019-10-28"}` != string(data) {
+		t.Errorf("Expected dates to be written as ISO 8601, but was %s", data)
+	}
+
+	decoded := Certification{}
+	if err := json.Unmarshal([]byte(`{"name": "Cert", "date_achieved": "10-28-2019", "date_expires": 2022}`), &decoded); err != nil {
+		t.Errorf("Failed to unmarshal legacy dates: %s", err.Error())
+	} else if "2019-10-28" != decoded.DateAchieved.String() || decoded.DateExpires == nil || "2022" != decoded.DateExpires.String() {
+		t.Errorf("Expected legacy dates to be parsed, but was %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"date_achieved": "sometime"}`), &decoded); err == nil {
+		t.Errorf("Expected an unparseable date to fail")
+	}
+
+	attr, err := dynamodbattribute.MarshalMap(cert)
+	if err != nil {
+		t.Fatalf("Failed to marshal certification: %s", err.Error())
+	}
+
+	if "2019-10-28" != aws.StringValue(attr["date_achieved"].S) {
+		t.Errorf("Expected date to be stored as ISO 8601, but was %v", attr["date_achieved"])
+	}
+
+	if _, ok := attr["date_expires"]; ok {
+		t.Errorf("Expected a missing expiry not to be stored")
+	}
+}
+
+func TestLegacyExperienceDates(t *testing.T) {
+	exp := Experience{}
+	if err := json.Unmarshal([]byte(`{"company": "Co", "start_month": "May", "start_year": 2020, "end_year": 2021}`), &exp); err != nil {
+		t.Errorf("Failed to unmarshal legacy experience: %s", err.Error())
+	} else if "2020-05" != exp.StartDate.String() || exp.EndDate == nil || "2021" != exp.EndDate.String() {
+		t.Errorf("Expected legacy month and year to become dates, but was %+v", exp)
+	}
+
+	exp = Experience{}
+	if err := json.Unmarshal([]byte(`{"company": "Co", "start_date": "2020-06", "start_month": "May", "start_year": 2020}`), &exp); err != nil {
+		t.Errorf("Failed to unmarshal experience: %s", err.Error())
+	} else if "2020-06" != exp.StartDate.String() || exp.EndDate != nil {
+		t.Errorf("Expected the typed date to win over the legacy fields, but was %+v", exp)
+	}
+
+	stored := map[string]*dynamodb.AttributeValue{
+		"company":     {S: aws.String("Co")},
+		"start_month": {S: aws.String("May")},
+		"start_year":  {N: aws.String("2020")},
+	}
+	user := &User{}
+	if err := dynamodbattribute.UnmarshalMap(map[string]*dynamodb.AttributeValue{"experience": {L: []*dynamodb.AttributeValue{{M: stored}}}}, user); err != nil {
+		t.Errorf("Failed to unmarshal stored user: %s", err.Error())
+	} else if len(user.Experience) != 1 || "2020-05" != user.Experience[0].StartDate.String() {
+		t.Errorf("Expected stored legacy dates to be read, but was %+v", user.Experience)
+	}
+
+	doc, err := UnmarshalUserYAML([]byte("experience:\n  - company: Co\n    start_month: May\n    start_year: 2020\n"))
+	if err != nil {
+		t.Errorf("Failed to unmarshal legacy YAML: %s", err.Error())
+	} else if "2020-05" != doc.User.Experience[0].StartDate.String() {
+		t.Errorf("Expected legacy YAML dates to be read, but was %+v", doc.User.Experience)
+	}
+}
+
+func TestValidateDates(t *testing.T) {
+	setup(t)
+	user.Experience[0].EndDate = &Date{Year: 2020, Month: time.April}
+	if err := ValidateUser(user); err == nil || ErrorEndBeforeStart != err.Error() {
+		t.Errorf("Expected error '%s', but was %v", ErrorEndBeforeStart, err)
+	} else if fieldErr := (*FieldError)(nil); !errors.As(err, &fieldErr) || "experience[0].end_date" != fieldErr.Field {
+		t.Errorf("Expected the error to point at the end date, but was %+v", err)
+	}
+
+	setup(t)
+	user.Certifications[0].DateExpires = &Date{Year: 2018}
+	if err := ValidateUser(user); err == nil || ErrorEndBeforeStart != err.Error() {
+		t.Errorf("Expected error '%s', but was %v", ErrorEndBeforeStart, err)
+	}
+
+	setup(t)
+	user.Experience[0].EndDate = &Date{Year: 2020}
+	if err := ValidateUser(user); err != nil {
+		t.Errorf("Expected an end year matching the start to be valid, but was %s", err.Error())
+	}
+}
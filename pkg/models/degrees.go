@@ -1,15 +1,15 @@
 package models
 
 type Degree struct {
-	Degree    string `json:"degree"`
-	Major     string `json:"major"`
-	School    string `json:"school"`
-	StartYear int    `json:"start_year"`
-	EndYear   int    `json:"end_year,omitempty"`
+	Degree    string `json:"degree" yaml:"degree" xml:"degree"`
+	Major     string `json:"major" yaml:"major" xml:"major"`
+	School    string `json:"school" yaml:"school" xml:"school"`
+	StartYear int    `json:"start_year" yaml:"start_year" xml:"start_year"`
+	EndYear   int    `json:"end_year,omitempty" yaml:"end_year,omitempty" xml:"end_year,omitempty"`
 }
 
 type DegreeKey struct {
-	Degree string `json:"degree"`
-	Major  string `json:"major"`
-	School string `json:"school"`
+	Degree string `json:"degree" yaml:"degree" xml:"degree"`
+	Major  string `json:"major" yaml:"major" xml:"major"`
+	School string `json:"school" yaml:"school" xml:"school"`
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Sentinel errors for each of the Error messages, so callers can match them with errors.Is however they've been
+// wrapped. FieldError and ValidationError unwrap to them.
+var (
+	ErrDuplicateEntry         = errors.New(ErrorDuplicateEntry)
+	ErrEndBeforeStart         = errors.New(ErrorEndBeforeStart)
+	ErrFieldRequired          = errors.New(ErrorFieldRequired)
+	ErrIdempotencyKeyInUse    = errors.New(ErrorIdempotencyKeyInUse)
+	ErrInvalidApiKeyId        = errors.New(ErrorInvalidApiKeyId)
+	ErrInvalidDate            = errors.New(ErrorInvalidDate)
+	ErrInvalidEmail           = errors.New(ErrorInvalidEmail)
+	ErrInvalidIdempotencyKey  = errors.New(ErrorInvalidIdempotencyKey)
+	ErrInvalidOrgId           = errors.New(ErrorInvalidOrgId)
+	ErrInvalidPhoneNumber     = errors.New(ErrorInvalidPhoneNumber)
+	ErrInvalidResumeId        = errors.New(ErrorInvalidResumeId)
+	ErrInvalidRole            = errors.New(ErrorInvalidRole)
+	ErrInvalidSecretHash      = errors.New(ErrorInvalidSecretHash)
+	ErrInvalidShareId         = errors.New(ErrorInvalidShareId)
+	ErrInvalidTenantId        = errors.New(ErrorInvalidTenantId)
+	ErrInvalidUrl             = errors.New(ErrorInvalidUrl)
+	ErrItemTooLarge           = errors.New(ErrorItemTooLarge)
+	ErrLastOwner              = errors.New(ErrorLastOwner)
+	ErrInvalidUserId          = errors.New(ErrorInvalidUserId)
+	ErrMembershipChanged      = errors.New(ErrorMembershipChanged)
+	ErrInvalidVisibility      = errors.New(ErrorInvalidVisibility)
+	ErrNoResultsFound         = errors.New(ErrorNoResultsFound)
+	ErrOrgAlreadyExists       = errors.New(ErrorOrgAlreadyExists)
+	ErrScopesNotProvided      = errors.New(ErrorScopesNotProvided)
+	ErrShareUnavailable       = errors.New(ErrorShareUnavailable)
+	ErrUnknownField           = errors.New(ErrorUnknownField)
+	ErrUnknownResumeEntry     = errors.New(ErrorUnknownResumeEntry)
+	ErrUnknownVisibilityField = errors.New(ErrorUnknownVisibilityField)
+	ErrYearOutOfRange         = errors.New(ErrorYearOutOfRange)
+)
+
+// isConditionalCheckFailed reports whether a conditional write was rejected because its condition didn't hold
+func isConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
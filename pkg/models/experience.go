@@ -1,16 +1,100 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"gopkg.in/yaml.v3"
+)
+
+// Experience is a position held. EndDate is nil while the position is current.
 type Experience struct {
-	Company          string   `json:"company"`
-	JobTitle         string   `json:"job_title"`
-	StartMonth       string   `json:"start_month"`
-	StartYear        int      `json:"start_year"`
-	EndMonth         string   `json:"end_month,omitempty"`
-	EndYear          int      `json:"end_year,omitempty"`
-	Responsibilities []string `json:"responsibilities,omitempty"`
+	Company          string   `json:"company" yaml:"company" xml:"company"`
+	JobTitle         string   `json:"job_title" yaml:"job_title" xml:"job_title"`
+	StartDate        Date     `json:"start_date" yaml:"start_date" xml:"start_date" dynamodbav:"start_date"`
+	EndDate          *Date    `json:"end_date,omitempty" yaml:"end_date,omitempty" xml:"end_date,omitempty" dynamodbav:"end_date,omitempty"`
+	Responsibilities []string `json:"responsibilities,omitempty" yaml:"responsibilities,omitempty" xml:"responsibilities>responsibility,omitempty"`
 }
 
 type ExperienceKey struct {
-	Company  string `json:"company"`
-	JobTitle string `json:"job_title"`
+	Company  string `json:"company" yaml:"company" xml:"company"`
+	JobTitle string `json:"job_title" yaml:"job_title" xml:"job_title"`
+}
+
+// legacyExperienceDates are the separate month and year fields experience was written with before dates were typed.
+// They are still read from request bodies and stored items, and are used when the typed date is missing.
+type legacyExperienceDates struct {
+	StartMonth string `json:"start_month" yaml:"start_month" dynamodbav:"start_month"`
+	StartYear  int    `json:"start_year" yaml:"start_year" dynamodbav:"start_year"`
+	EndMonth   string `json:"end_month" yaml:"end_month" dynamodbav:"end_month"`
+	EndYear    int    `json:"end_year" yaml:"end_year" dynamodbav:"end_year"`
+}
+
+// plainExperience has Experience's fields without its unmarshal methods, so they can decode into it without recursing
+type plainExperience Experience
+
+// experienceFields is every field experience may be written with, so request bodies can be decoded in one strict pass.
+// A decoder's strictness doesn't reach into an UnmarshalJSON or UnmarshalYAML, so it has to be applied here again.
+type experienceFields struct {
+	plainExperience       `yaml:",inline"`
+	legacyExperienceDates `yaml:",inline"`
+}
+
+func (e *Experience) UnmarshalJSON(data []byte) error {
+	fields := experienceFields{plainExperience: plainExperience(*e)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+
+	*e = Experience(fields.plainExperience)
+	return e.applyLegacyDates(fields.legacyExperienceDates)
+}
+
+func (e *Experience) UnmarshalYAML(node *yaml.Node) error {
+	fields := experienceFields{plainExperience: plainExperience(*e)}
+	if err := checkKnownYAMLFields(node, fields); err != nil {
+		return err
+	}
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+
+	*e = Experience(fields.plainExperience)
+	return e.applyLegacyDates(fields.legacyExperienceDates)
+}
+
+func (e *Experience) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	legacy := legacyExperienceDates{}
+	if err := dynamodbattribute.UnmarshalMap(av.M, (*plainExperience)(e)); err != nil {
+		return err
+	}
+	if err := dynamodbattribute.UnmarshalMap(av.M, &legacy); err != nil {
+		return err
+	}
+
+	return e.applyLegacyDates(legacy)
+}
+
+func (e *Experience) applyLegacyDates(legacy legacyExperienceDates) error {
+	if e.StartDate.IsZero() {
+		start, err := NewMonthDate(legacy.StartMonth, legacy.StartYear)
+		if err != nil {
+			return err
+		}
+		e.StartDate = start
+	}
+
+	if e.EndDate == nil && legacy.EndYear != 0 {
+		end, err := NewMonthDate(legacy.EndMonth, legacy.EndYear)
+		if err != nil {
+			return err
+		}
+		e.EndDate = &end
+	}
+
+	return nil
 }
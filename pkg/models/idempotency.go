@@ -0,0 +1,180 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorIdempotencyKeyInUse   = "idempotency key is already in use"
+	ErrorInvalidIdempotencyKey = "invalid idempotency key"
+)
+
+var IdempotencyTable = "resume_idempotency"
+
+// IdempotencyRecord remembers the response to the first request made with an idempotency key so retries can be
+// answered with it. It is written incomplete when the request starts and completed once it has a response. ExpiresAt
+// is a unix timestamp so the table can use it for TTL.
+type IdempotencyRecord struct {
+	TenantId        string            `dynamodbav:"-"`
+	Key             string            `dynamodbav:"idempotency_key"`
+	Fingerprint     string            `dynamodbav:"fingerprint"`
+	Completed       bool              `dynamodbav:"completed"`
+	StatusCode      int               `dynamodbav:"status_code"`
+	Headers         map[string]string `dynamodbav:"headers,omitempty"`
+	Body            string            `dynamodbav:"body,omitempty"`
+	IsBase64Encoded bool              `dynamodbav:"is_base64_encoded"`
+	ExpiresAt       int64             `dynamodbav:"expires_at"`
+}
+
+type IdempotencyKey struct {
+	TenantId string
+	Key      string
+}
+
+// ClaimIdempotencyKey writes the incomplete record unless an unexpired one already holds the key, in which case it
+// returns ErrIdempotencyKeyInUse
+func ClaimIdempotencyKey(ctx context.Context, record *IdempotencyRecord, now time.Time, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	input, err := getIdempotencyPutInput(record)
+	if err != nil {
+		logger.Error("Failed to construct input for claim idempotency key", zap.Error(err))
+		return err
+	}
+
+	// TTL deletes expired items lazily, so an expired record may still be there to be replaced
+	input.ConditionExpression = aws.String("attribute_not_exists(pk) OR expires_at <= :now")
+	input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+		":now": {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+	}
+
+	if _, err := svc.PutItemWithContext(ctx, input); err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrIdempotencyKeyInUse
+		}
+
+		logger.Error("Failed to claim idempotency key", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// PutIdempotencyRecord stores the completed record over the claim, as long as it is still the claim that was made.
+// Once a claim's lease runs out another request may have claimed the key, and this request's response mustn't replace
+// theirs, so it returns ErrIdempotencyKeyInUse instead.
+func PutIdempotencyRecord(ctx context.Context, record *IdempotencyRecord, claim *IdempotencyRecord, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	input, err := getIdempotencyPutInput(record)
+	if err != nil {
+		logger.Error("Failed to construct input for put idempotency record", zap.Error(err))
+		return err
+	}
+	input.ConditionExpression, input.ExpressionAttributeValues = claimCondition(claim)
+
+	if _, err := svc.PutItemWithContext(ctx, input); err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrIdempotencyKeyInUse
+		}
+
+		logger.Error("Failed to insert idempotency record into database", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetIdempotencyRecord treats an expired record the same as a missing one
+func GetIdempotencyRecord(ctx context.Context, key *IdempotencyKey, now time.Time, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*IdempotencyRecord, error) {
+	pk, err := idempotencyKey(key)
+	if err != nil {
+		logger.Error("Failed to get input to get idempotency record", zap.Error(err))
+		return nil, err
+	}
+
+	record := &IdempotencyRecord{}
+	if err := getItem(ctx, IdempotencyTable, map[string]*dynamodb.AttributeValue{PartitionKey: pk}, record, svc, logger); err != nil {
+		return nil, err
+	}
+
+	if record.ExpiresAt <= now.Unix() {
+		return nil, ErrNoResultsFound
+	}
+
+	record.TenantId = key.TenantId
+	return record, nil
+}
+
+// DeleteIdempotencyRecord releases the claim, so a request that failed can be tried again with its key. Like
+// PutIdempotencyRecord it only removes the claim that was made, returning ErrIdempotencyKeyInUse when another request
+// has the key now.
+func DeleteIdempotencyRecord(ctx context.Context, claim *IdempotencyRecord, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	pk, err := idempotencyKey(&IdempotencyKey{TenantId: claim.TenantId, Key: claim.Key})
+	if err != nil {
+		logger.Error("Failed to get input to delete idempotency record", zap.Error(err))
+		return err
+	}
+
+	condition, values := claimCondition(claim)
+	_, err = svc.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		Key:                       map[string]*dynamodb.AttributeValue{PartitionKey: pk},
+		TableName:                 aws.String(IdempotencyTable),
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrIdempotencyKeyInUse
+		}
+
+		logger.Error("Failed to delete idempotency record", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// claimCondition matches the incomplete record written by ClaimIdempotencyKey. Its expiry tells it apart from a later
+// claim by a retry of the same request.
+func claimCondition(claim *IdempotencyRecord) (*string, map[string]*dynamodb.AttributeValue) {
+	return aws.String("fingerprint = :fingerprint AND expires_at = :lease AND completed = :false"), map[string]*dynamodb.AttributeValue{
+		":fingerprint": {S: aws.String(claim.Fingerprint)},
+		":lease":       {N: aws.String(strconv.FormatInt(claim.ExpiresAt, 10))},
+		":false":       {BOOL: aws.Bool(false)},
+	}
+}
+
+func getIdempotencyPutInput(record *IdempotencyRecord) (*dynamodb.PutItemInput, error) {
+	pk, err := idempotencyKey(&IdempotencyKey{TenantId: record.TenantId, Key: record.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		return nil, err
+	}
+	item[PartitionKey] = pk
+
+	if err := checkItemSize(item); err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(IdempotencyTable),
+	}, nil
+}
+
+func idempotencyKey(key *IdempotencyKey) (*dynamodb.AttributeValue, error) {
+	if len(key.Key) == 0 {
+		return nil, &FieldError{Field: "idempotency_key", Err: ErrInvalidIdempotencyKey}
+	}
+
+	return tenantKey(key.TenantId, key.Key)
+}
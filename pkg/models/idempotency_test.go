@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	now := time.Unix(1600000000, 0)
+	record := &IdempotencyRecord{TenantId: "tenant1", Key: "auth0|user1#key1", Fingerprint: "abc", ExpiresAt: now.Add(time.Hour).Unix()}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if IdempotencyTable != *input.TableName {
+			t.Errorf("Expected table name to be '%s', but was '%s'", IdempotencyTable, *input.TableName)
+		}
+		if pk := *input.Item[PartitionKey].S; pk != "tenant1#auth0|user1#key1" {
+			t.Errorf("Expected the key to be tenant scoped, but was '%s'", pk)
+		}
+		if input.ConditionExpression == nil || *input.ExpressionAttributeValues[":now"].N != "1600000000" {
+			t.Errorf("Expected the claim to be conditional on the key being free")
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if err := ClaimIdempotencyKey(context.Background(), record, now, svc, logger); err != nil {
+		t.Errorf("Failed to claim idempotency key: %s", err.Error())
+	}
+
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	if err := ClaimIdempotencyKey(context.Background(), record, now, svc, logger); !errors.Is(err, ErrIdempotencyKeyInUse) {
+		t.Errorf("Expected error to be '%s', but was '%v'", ErrorIdempotencyKeyInUse, err)
+	}
+
+	if err := ClaimIdempotencyKey(context.Background(), &IdempotencyRecord{TenantId: "tenant1"}, now, svc, logger); !errors.Is(err, ErrInvalidIdempotencyKey) {
+		t.Errorf("Expected error to be '%s', but was '%v'", ErrorInvalidIdempotencyKey, err)
+	}
+}
+
+func TestGetIdempotencyRecord(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	now := time.Unix(1600000000, 0)
+	stored := &IdempotencyRecord{Fingerprint: "abc", Completed: true, StatusCode: 201, Body: "{}", ExpiresAt: now.Add(time.Minute).Unix()}
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		item, _ := dynamodbattribute.MarshalMap(stored)
+		return &dynamodb.GetItemOutput{Item: item}, nil
+	}
+
+	key := &IdempotencyKey{TenantId: "tenant1", Key: "auth0|user1#key1"}
+	if record, err := GetIdempotencyRecord(context.Background(), key, now, svc, logger); err != nil {
+		t.Errorf("Failed to get idempotency record: %s", err.Error())
+	} else if record.StatusCode != 201 || !record.Completed || record.TenantId != "tenant1" {
+		t.Errorf("Expected the stored record, but got %+v", record)
+	}
+
+	if _, err := GetIdempotencyRecord(context.Background(), key, now.Add(time.Hour), svc, logger); !errors.Is(err, ErrNoResultsFound) {
+		t.Errorf("Expected an expired record to be treated as missing, but got '%v'", err)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	ErrorItemTooLarge = "item is larger than DynamoDB's 400 KB limit"
+	MaxItemBytes      = 400 * 1024
+)
+
+// checkItemSize catches items DynamoDB would reject for their size before they are sent, naming the largest attribute
+// since it is the one most worth trimming
+func checkItemSize(item map[string]*dynamodb.AttributeValue) error {
+	size, largest, largestSize := 0, "", 0
+	for name, value := range item {
+		attrSize := len(name) + attributeSize(value)
+		size += attrSize
+		if attrSize > largestSize {
+			largest, largestSize = name, attrSize
+		}
+	}
+
+	if size <= MaxItemBytes {
+		return nil
+	}
+
+	return &FieldError{Field: largest, Code: CodeOutOfRange, Err: fmt.Errorf("%w, it would be %d bytes with %s taking %d", ErrItemTooLarge, size, largest, largestSize)}
+}
+
+// attributeSize follows DynamoDB's rules for item size closely enough to tell when an item is over the limit
+func attributeSize(av *dynamodb.AttributeValue) int {
+	switch {
+	case av == nil:
+		return 0
+	case av.S != nil:
+		return len(*av.S)
+	case av.N != nil:
+		return numberSize(*av.N)
+	case av.B != nil:
+		return len(av.B)
+	case av.BOOL != nil, av.NULL != nil:
+		return 1
+	case av.M != nil:
+		size := 3
+		for name, value := range av.M {
+			size += len(name) + attributeSize(value) + 1
+		}
+		return size
+	case av.L != nil:
+		size := 3
+		for _, value := range av.L {
+			size += attributeSize(value) + 1
+		}
+		return size
+	}
+
+	size := 0
+	for _, s := range av.SS {
+		size += len(*s)
+	}
+	for _, n := range av.NS {
+		size += numberSize(*n)
+	}
+	for _, b := range av.BS {
+		size += len(b)
+	}
+	return size
+}
+
+// numberSize is roughly one byte per two significant digits, plus one
+func numberSize(n string) int {
+	return (len(n)+1)/2 + 1
+}
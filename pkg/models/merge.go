@@ -0,0 +1,152 @@
+package models
+
+func (e Experience) Key() ExperienceKey {
+	return ExperienceKey{Company: e.Company, JobTitle: e.JobTitle}
+}
+
+func (d Degree) Key() DegreeKey {
+	return DegreeKey{Degree: d.Degree, Major: d.Major, School: d.School}
+}
+
+func (s Skill) Key() SkillKey {
+	return SkillKey{Name: s.Name}
+}
+
+func (c Certification) Key() CertificationKey {
+	return CertificationKey{CertificationName: c.Name}
+}
+
+// MergeUser folds imported into existing without duplicating entries. Entries are matched on their key types, and
+// anything already set on existing wins so hand curated content is never replaced by an import; imported values only
+// fill in the blanks. New entries are appended in the order they were imported.
+func MergeUser(existing *User, imported *User) *User {
+	merged := *existing
+
+	merged.Email = firstNonEmpty(existing.Email, imported.Email)
+	merged.Github = firstNonEmpty(existing.Github, imported.Github)
+	merged.GivenName = firstNonEmpty(existing.GivenName, imported.GivenName)
+	merged.Location = firstNonEmpty(existing.Location, imported.Location)
+	merged.Linkedin = firstNonEmpty(existing.Linkedin, imported.Linkedin)
+	merged.PhoneNumber = firstNonEmpty(existing.PhoneNumber, imported.PhoneNumber)
+	merged.Summary = firstNonEmpty(existing.Summary, imported.Summary)
+	merged.SurName = firstNonEmpty(existing.SurName, imported.SurName)
+
+	merged.Experience = append([]Experience{}, existing.Experience...)
+	experienceIdx := make(map[ExperienceKey]int)
+	for i, exp := range merged.Experience {
+		experienceIdx[exp.Key()] = i
+	}
+	for _, exp := range imported.Experience {
+		if i, ok := experienceIdx[exp.Key()]; ok {
+			merged.Experience[i] = mergeExperience(merged.Experience[i], exp)
+		} else {
+			experienceIdx[exp.Key()] = len(merged.Experience)
+			merged.Experience = append(merged.Experience, exp)
+		}
+	}
+
+	merged.Degrees = append([]Degree{}, existing.Degrees...)
+	degreeIdx := make(map[DegreeKey]int)
+	for i, degree := range merged.Degrees {
+		degreeIdx[degree.Key()] = i
+	}
+	for _, degree := range imported.Degrees {
+		if i, ok := degreeIdx[degree.Key()]; ok {
+			merged.Degrees[i] = mergeDegree(merged.Degrees[i], degree)
+		} else {
+			degreeIdx[degree.Key()] = len(merged.Degrees)
+			merged.Degrees = append(merged.Degrees, degree)
+		}
+	}
+
+	merged.Skills = append([]Skill{}, existing.Skills...)
+	skillIdx := make(map[SkillKey]int)
+	for i, skill := range merged.Skills {
+		skillIdx[skill.Key()] = i
+	}
+	for _, skill := range imported.Skills {
+		if i, ok := skillIdx[skill.Key()]; ok {
+			if merged.Skills[i].YearsOfExperience == 0 {
+				merged.Skills[i].YearsOfExperience = skill.YearsOfExperience
+			}
+		} else {
+			skillIdx[skill.Key()] = len(merged.Skills)
+			merged.Skills = append(merged.Skills, skill)
+		}
+	}
+
+	merged.Certifications = append([]Certification{}, existing.Certifications...)
+	certIdx := make(map[CertificationKey]int)
+	for i, cert := range merged.Certifications {
+		certIdx[cert.Key()] = i
+	}
+	for _, cert := range imported.Certifications {
+		if i, ok := certIdx[cert.Key()]; ok {
+			merged.Certifications[i] = mergeCertification(merged.Certifications[i], cert)
+		} else {
+			certIdx[cert.Key()] = len(merged.Certifications)
+			merged.Certifications = append(merged.Certifications, cert)
+		}
+	}
+
+	return &merged
+}
+
+func mergeExperience(existing Experience, imported Experience) Experience {
+	existing.StartDate = firstNonZeroDate(existing.StartDate, imported.StartDate)
+	if existing.EndDate == nil {
+		existing.EndDate = imported.EndDate
+	}
+	if len(existing.Responsibilities) == 0 {
+		existing.Responsibilities = imported.Responsibilities
+	}
+
+	return existing
+}
+
+func mergeDegree(existing Degree, imported Degree) Degree {
+	existing.StartYear = firstNonZero(existing.StartYear, imported.StartYear)
+	existing.EndYear = firstNonZero(existing.EndYear, imported.EndYear)
+
+	return existing
+}
+
+func mergeCertification(existing Certification, imported Certification) Certification {
+	existing.DateAchieved = firstNonZeroDate(existing.DateAchieved, imported.DateAchieved)
+	existing.BadgeLink = firstNonEmpty(existing.BadgeLink, imported.BadgeLink)
+	if existing.DateExpires == nil {
+		existing.DateExpires = imported.DateExpires
+	}
+
+	return existing
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if len(value) > 0 {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func firstNonZero(values ...int) int {
+	for _, value := range values {
+		if value != 0 {
+			return value
+		}
+	}
+
+	return 0
+}
+
+func firstNonZeroDate(values ...Date) Date {
+	for _, value := range values {
+		if !value.IsZero() {
+			return value
+		}
+	}
+
+	return Date{}
+}
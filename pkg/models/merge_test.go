@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeUser(t *testing.T) {
+	setup(t)
+
+	imported := &User{
+		Email:     "imported@domain.com",
+		GivenName: "Johnny",
+		Location:  "",
+		Experience: []Experience{
+			{Company: "Co", JobTitle: "SRE", StartDate: Date{Year: 2019, Month: time.April}, Responsibilities: []string{"imported"}},
+			{Company: "New Co", JobTitle: "Dev", StartDate: Date{Year: 2021}},
+		},
+		Degrees: []Degree{
+			{Degree: "BS", Major: "CS", School: "University", StartYear: 2016},
+			{Degree: "MS", Major: "CS", School: "University", StartYear: 2022},
+		},
+		Skills:         []Skill{{Name: "Go", YearsOfExperience: 5}, {Name: "Terraform"}},
+		Certifications: []Certification{{Name: "Some Cert", DateAchieved: Date{Year: 2019}}, {Name: "Other Cert"}},
+	}
+
+	merged := MergeUser(user, imported)
+	if user.Email != merged.Email || user.GivenName != merged.GivenName {
+		t.Errorf("Expected existing contact details to win, but got '%s' and '%s'", merged.Email, merged.GivenName)
+	}
+
+	if len(merged.Experience) != 2 {
+		t.Fatalf("Expected 2 positions after merge, but got %d", len(merged.Experience))
+	}
+
+	if "2020-05" != merged.Experience[0].StartDate.String() {
+		t.Errorf("Expected existing dates to win, but got %s", merged.Experience[0].StartDate)
+	}
+
+	if len(merged.Experience[0].Responsibilities) != 2 {
+		t.Errorf("Expected existing responsibilities to win, but got %v", merged.Experience[0].Responsibilities)
+	}
+
+	if len(merged.Degrees) != 2 || 2017 != merged.Degrees[0].StartYear {
+		t.Errorf("Expected degrees to merge by key, but got %+v", merged.Degrees)
+	}
+
+	if len(merged.Skills) != 2 || 2 != merged.Skills[0].YearsOfExperience {
+		t.Errorf("Expected skills to merge by name, but got %+v", merged.Skills)
+	}
+
+	if len(merged.Certifications) != 2 || "2019-10-28" != merged.Certifications[0].DateAchieved.String() {
+		t.Errorf("Expected certifications to merge by name, but got %+v", merged.Certifications)
+	}
+
+	if len(user.Experience) != 1 {
+		t.Errorf("Expected merge to leave the existing user untouched, but it has %d positions", len(user.Experience))
+	}
+
+	filled := MergeUser(&User{UserId: "user1", Experience: []Experience{{Company: "Co", JobTitle: "SRE"}}}, imported)
+	if "imported@domain.com" != filled.Email {
+		t.Errorf("Expected blank email to be filled by import, but was '%s'", filled.Email)
+	}
+
+	if 2019 != filled.Experience[0].StartDate.Year || len(filled.Experience[0].Responsibilities) != 1 {
+		t.Errorf("Expected blank position fields to be filled by import, but got %+v", filled.Experience[0])
+	}
+}
@@ -0,0 +1,434 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorInvalidOrgId      = "invalid org_id"
+	ErrorInvalidRole       = "invalid role"
+	ErrorLastOwner         = "organization must keep at least one owner"
+	ErrorMembershipChanged = "membership was changed by another request"
+	ErrorOrgAlreadyExists  = "organization already exists"
+)
+
+var (
+	MembershipsTable   = "resume_membership"
+	OrganizationsTable = "resume_org"
+)
+
+// Role is what a member may do with the resumes their organization owns
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+func (r Role) IsValid() bool {
+	return r == RoleOwner || r == RoleEditor || r == RoleViewer
+}
+
+// CanEdit reports whether the role may change or delete the organization's resumes
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether the role may change the organization's memberships
+func (r Role) CanManage() bool {
+	return r == RoleOwner
+}
+
+// Organization is a shared workspace that owns the resumes of every user whose OrgId points at it. OwnerCount is kept
+// in step with its owner memberships so that removing an owner can be conditioned on another one remaining.
+type Organization struct {
+	TenantId   string `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	OrgId      string `json:"org_id" yaml:"org_id" xml:"org_id"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	CreatedAt  int64  `json:"created_at" yaml:"created_at" xml:"created_at"`
+	OwnerCount int    `json:"-" yaml:"-" xml:"-" dynamodbav:"owner_count,omitempty"`
+}
+
+type OrganizationKey struct {
+	TenantId string `json:"-"`
+	OrgId    string `json:"org_id"`
+}
+
+// Membership gives the user (the caller's user_id, not a resume) a role in the organization
+type Membership struct {
+	TenantId string `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	OrgId    string `json:"org_id" yaml:"org_id" xml:"org_id"`
+	UserId   string `json:"user_id" yaml:"user_id" xml:"user_id"`
+	Role     Role   `json:"role" yaml:"role" xml:"role"`
+}
+
+type MembershipKey struct {
+	TenantId string `json:"-"`
+	OrgId    string `json:"org_id"`
+	UserId   string `json:"user_id"`
+}
+
+// PutOrganization creates the organization, failing with ErrorOrgAlreadyExists rather than overwriting one
+func PutOrganization(ctx context.Context, org *Organization, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if len(org.OrgId) == 0 {
+		logger.Error("OrgId is empty")
+		return &FieldError{Field: "org_id", Err: ErrInvalidOrgId}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(org)
+	if err == nil {
+		err = tenantItem(item, org.TenantId, org.OrgId)
+	}
+	if err != nil {
+		logger.Error("Failed to construct input for create organization", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(OrganizationsTable),
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			logger.Warn("Organization already exists", zap.String("org_id", org.OrgId))
+			return &FieldError{Field: "org_id", Err: ErrOrgAlreadyExists}
+		}
+
+		logger.Error("Failed to insert new organization into database", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully inserted new organization into database", zap.String("org_id", org.OrgId))
+	return nil
+}
+
+func GetOrganization(ctx context.Context, key *OrganizationKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*Organization, error) {
+	pk, err := tenantKey(key.TenantId, key.OrgId)
+	if err != nil {
+		logger.Error("Failed to get input to get organization", zap.Error(err))
+		return nil, err
+	}
+
+	org := &Organization{}
+	if err := getItem(ctx, OrganizationsTable, map[string]*dynamodb.AttributeValue{PartitionKey: pk}, org, svc, logger); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func PutMembership(ctx context.Context, membership *Membership, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if len(membership.OrgId) == 0 {
+		logger.Error("OrgId is empty")
+		return &FieldError{Field: "org_id", Err: ErrInvalidOrgId}
+	}
+
+	if len(membership.UserId) == 0 {
+		logger.Error("UserId is empty")
+		return &FieldError{Field: "user_id", Err: ErrInvalidUserId}
+	}
+
+	if !membership.Role.IsValid() {
+		logger.Error("Role is invalid", zap.String("role", string(membership.Role)))
+		return &FieldError{Field: "role", Err: ErrInvalidRole}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(membership)
+	if err == nil {
+		err = tenantItem(item, membership.TenantId, membership.OrgId)
+	}
+	if err != nil {
+		logger.Error("Failed to construct input for put membership", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(MembershipsTable),
+	})
+	if err != nil {
+		logger.Error("Failed to insert membership into database", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully inserted membership into database", zap.String("org_id", membership.OrgId), zap.String("user_id", membership.UserId), zap.String("role", string(membership.Role)))
+	return nil
+}
+
+func GetMembership(ctx context.Context, key *MembershipKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*Membership, error) {
+	keyAttr, err := membershipKey(key)
+	if err != nil {
+		logger.Error("Failed to get input to get membership", zap.Error(err))
+		return nil, err
+	}
+
+	membership := &Membership{}
+	if err := getItem(ctx, MembershipsTable, keyAttr, membership, svc, logger); err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+func GetMemberships(ctx context.Context, key *OrganizationKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) ([]Membership, error) {
+	pk, err := tenantKey(key.TenantId, key.OrgId)
+	if err != nil {
+		logger.Error("Failed to get input to query memberships", zap.Error(err))
+		return nil, err
+	}
+
+	items, err := queryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(MembershipsTable),
+		KeyConditionExpression:    aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":pk": pk},
+	}, svc)
+	if err != nil {
+		logger.Error("Failed to query memberships", zap.Error(err), zap.String("org_id", key.OrgId))
+		return nil, err
+	}
+
+	var memberships []Membership
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &memberships); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes to Membership objects", zap.Error(err))
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+// SetMembershipRole gives the member the role, adding them to the organization if they aren't in it yet
+func SetMembershipRole(ctx context.Context, membership *Membership, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if !membership.Role.IsValid() {
+		logger.Error("Role is invalid", zap.String("role", string(membership.Role)))
+		return &FieldError{Field: "role", Err: ErrInvalidRole}
+	}
+
+	key := &MembershipKey{TenantId: membership.TenantId, OrgId: membership.OrgId, UserId: membership.UserId}
+	return changeMembership(ctx, key, membership.Role, svc, logger)
+}
+
+// RemoveMembership takes the member out of the organization
+func RemoveMembership(ctx context.Context, key *MembershipKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	return changeMembership(ctx, key, "", svc, logger)
+}
+
+// changeMembership writes the membership, or deletes it when role is empty, in the same transaction as any change to
+// the organization's owner count. The membership is conditioned on still having the role it was read with, and an owner
+// leaving on owner_count staying above zero, so two owners stepping down at once can't both succeed. It returns
+// ErrLastOwner when the change would leave no owner, and ErrMembershipChanged when another request got there first.
+func changeMembership(ctx context.Context, key *MembershipKey, role Role, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if len(key.OrgId) == 0 {
+		logger.Error("OrgId is empty")
+		return &FieldError{Field: "org_id", Err: ErrInvalidOrgId}
+	}
+
+	if len(key.UserId) == 0 {
+		logger.Error("UserId is empty")
+		return &FieldError{Field: "user_id", Err: ErrInvalidUserId}
+	}
+
+	keyAttr, err := membershipKey(key)
+	if err != nil {
+		logger.Error("Failed to get input to change membership", zap.Error(err))
+		return err
+	}
+
+	current, err := GetMembership(ctx, key, svc, logger)
+	if errors.Is(err, ErrNoResultsFound) {
+		current = nil
+	} else if err != nil {
+		return err
+	}
+
+	if current == nil && len(role) == 0 {
+		return nil
+	}
+
+	membershipItem := &dynamodb.TransactWriteItem{}
+	condition := aws.String("attribute_not_exists(pk)")
+	var names map[string]*string
+	var values map[string]*dynamodb.AttributeValue
+	if current != nil {
+		// role is a reserved word
+		condition = aws.String("#role = :current")
+		names = map[string]*string{"#role": aws.String("role")}
+		values = map[string]*dynamodb.AttributeValue{":current": {S: aws.String(string(current.Role))}}
+	}
+	if len(role) == 0 {
+		membershipItem.Delete = &dynamodb.Delete{
+			Key:                       keyAttr,
+			TableName:                 aws.String(MembershipsTable),
+			ConditionExpression:       condition,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+		}
+	} else {
+		item, err := dynamodbattribute.MarshalMap(&Membership{TenantId: key.TenantId, OrgId: key.OrgId, UserId: key.UserId, Role: role})
+		if err == nil {
+			err = tenantItem(item, key.TenantId, key.OrgId)
+		}
+		if err != nil {
+			logger.Error("Failed to construct input for put membership", zap.Error(err))
+			return err
+		}
+		membershipItem.Put = &dynamodb.Put{
+			Item:                      item,
+			TableName:                 aws.String(MembershipsTable),
+			ConditionExpression:       condition,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+		}
+	}
+	items := []*dynamodb.TransactWriteItem{membershipItem}
+	wasOwner, isOwner := current != nil && current.Role == RoleOwner, role == RoleOwner
+	if wasOwner != isOwner {
+		orgKey := &OrganizationKey{TenantId: key.TenantId, OrgId: key.OrgId}
+		if err := countOwners(ctx, orgKey, svc, logger); err != nil {
+			return err
+		}
+
+		pk, _ := tenantKey(key.TenantId, key.OrgId)
+		update := &dynamodb.Update{
+			Key:                       map[string]*dynamodb.AttributeValue{PartitionKey: pk},
+			TableName:                 aws.String(OrganizationsTable),
+			UpdateExpression:          aws.String("ADD owner_count :change"),
+			ConditionExpression:       aws.String("attribute_exists(pk)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":change": {N: aws.String("1")}},
+		}
+		if wasOwner {
+			update.ConditionExpression = aws.String("owner_count > :one")
+			update.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+				":change": {N: aws.String("-1")},
+				":one":    {N: aws.String("1")},
+			}
+		}
+		items = append(items, &dynamodb.TransactWriteItem{Update: update})
+	}
+
+	_, err = svc.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for i, reason := range canceled.CancellationReasons {
+			if reason == nil || reason.Code == nil || *reason.Code != "ConditionalCheckFailed" {
+				continue
+			} else if i == 0 {
+				logger.Warn("Membership changed while it was being updated", zap.String("org_id", key.OrgId), zap.String("user_id", key.UserId))
+				return ErrMembershipChanged
+			} else if wasOwner {
+				logger.Warn("Refused to remove the last owner of organization", zap.String("org_id", key.OrgId), zap.String("user_id", key.UserId))
+				return ErrLastOwner
+			} else {
+				return ErrNoResultsFound
+			}
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to change membership", zap.Error(err), zap.String("org_id", key.OrgId), zap.String("user_id", key.UserId))
+		return err
+	}
+
+	logger.Info("Changed membership", zap.String("org_id", key.OrgId), zap.String("user_id", key.UserId), zap.String("role", string(role)))
+	return nil
+}
+
+// countOwners sets the owner count of an organization that was created before it was kept. Every organization has
+// an owner, so a count of zero means it was never set.
+func countOwners(ctx context.Context, key *OrganizationKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	org, err := GetOrganization(ctx, key, svc, logger)
+	if err != nil || org.OwnerCount > 0 {
+		return err
+	}
+
+	memberships, err := GetMemberships(ctx, key, svc, logger)
+	if err != nil {
+		return err
+	}
+
+	owners := 0
+	for _, membership := range memberships {
+		if membership.Role == RoleOwner {
+			owners++
+		}
+	}
+
+	pk, _ := tenantKey(key.TenantId, key.OrgId)
+	_, err = svc.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		Key:                       map[string]*dynamodb.AttributeValue{PartitionKey: pk},
+		TableName:                 aws.String(OrganizationsTable),
+		UpdateExpression:          aws.String("SET owner_count = :owners"),
+		ConditionExpression:       aws.String("attribute_not_exists(owner_count)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":owners": {N: aws.String(strconv.Itoa(owners))}},
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		logger.Error("Failed to count organization owners", zap.Error(err), zap.String("org_id", key.OrgId))
+		return err
+	}
+
+	return nil
+}
+
+// membershipKey partitions memberships by the organization within the tenant, sorted by user
+func membershipKey(key *MembershipKey) (map[string]*dynamodb.AttributeValue, error) {
+	pk, err := tenantKey(key.TenantId, key.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]*dynamodb.AttributeValue{
+		PartitionKey: pk,
+		"user_id":    {S: aws.String(key.UserId)},
+	}, nil
+}
+
+func getItem(ctx context.Context, table string, keyAttr map[string]*dynamodb.AttributeValue, out interface{}, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	result, err := svc.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		Key:       keyAttr,
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		logger.Error("Failed to get item", zap.Error(err), zap.String("table", table))
+		return err
+	}
+
+	if result.Item == nil {
+		logger.Warn("No item found", zap.String("table", table))
+		return ErrNoResultsFound
+	}
+
+	if err := dynamodbattribute.UnmarshalMap(result.Item, out); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes", zap.Error(err), zap.String("table", table))
+		return err
+	}
+
+	return nil
+}
+
+// queryAll follows LastEvaluatedKey until every page of the query has been read, since a single page stops at 1 MB.
+// Pages are fetched with QueryWithContext rather than QueryPagesWithContext so each one goes through the storage
+// wrapper's retries.
+func queryAll(ctx context.Context, input *dynamodb.QueryInput, svc dynamodbiface.DynamoDBAPI) ([]map[string]*dynamodb.AttributeValue, error) {
+	page := *input
+	var items []map[string]*dynamodb.AttributeValue
+	for {
+		result, err := svc.QueryWithContext(ctx, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, result.Items...)
+		if len(result.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+		page.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+func TestPutOrganization(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if OrganizationsTable != *input.TableName {
+			t.Errorf("Expected table name to be '%s', but was '%s'", OrganizationsTable, *input.TableName)
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if err := PutOrganization(context.Background(), &Organization{TenantId: "tenant1", OrgId: "org1", Name: "Team"}, svc, logger); err != nil {
+		t.Errorf("Failed to create organization when it should have been successful: %s", err.Error())
+	}
+
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	if err := PutOrganization(context.Background(), &Organization{TenantId: "tenant1", OrgId: "org1"}, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorOrgAlreadyExists != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorOrgAlreadyExists, err.Error())
+	}
+}
+
+func TestPutMembership(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if err := PutMembership(context.Background(), &Membership{TenantId: "tenant1", OrgId: "org1", UserId: "user1", Role: RoleEditor}, svc, logger); err != nil {
+		t.Errorf("Failed to put membership when it should have been successful: %s", err.Error())
+	}
+
+	if err := PutMembership(context.Background(), &Membership{TenantId: "tenant1", OrgId: "org1", UserId: "user1", Role: "admin"}, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorInvalidRole != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidRole, err.Error())
+	}
+}
+
+func TestGetMemberships(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	attr, _ := dynamodbattribute.MarshalMap(&Membership{OrgId: "org1", UserId: "user1", Role: RoleViewer})
+	mocks.QueryMock = func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		if "tenant1#org1" != *input.ExpressionAttributeValues[":pk"].S {
+			t.Errorf("Expected query to be for 'org1' in 'tenant1'")
+		}
+		return &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{attr}}, nil
+	}
+	if memberships, err := GetMemberships(context.Background(), &OrganizationKey{TenantId: "tenant1", OrgId: "org1"}, svc, logger); err != nil {
+		t.Errorf("Failed to get memberships: %s", err.Error())
+	} else if len(memberships) != 1 || RoleViewer != memberships[0].Role {
+		t.Errorf("Expected one viewer, but got %+v", memberships)
+	}
+
+	// Members past the first page are still returned
+	second, _ := dynamodbattribute.MarshalMap(&Membership{OrgId: "org1", UserId: "user2", Role: RoleOwner})
+	lastKey := map[string]*dynamodb.AttributeValue{PartitionKey: {S: aws.String("tenant1#org1")}, "user_id": {S: aws.String("user1")}}
+	mocks.QueryMock = func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		if input.ExclusiveStartKey == nil {
+			return &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{attr}, LastEvaluatedKey: lastKey}, nil
+		} else if "user1" != *input.ExclusiveStartKey["user_id"].S {
+			t.Errorf("Expected the second page to start after 'user1'")
+		}
+		return &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{second}}, nil
+	}
+	if memberships, err := GetMemberships(context.Background(), &OrganizationKey{TenantId: "tenant1", OrgId: "org1"}, svc, logger); err != nil {
+		t.Errorf("Failed to get memberships: %s", err.Error())
+	} else if len(memberships) != 2 || "user2" != memberships[1].UserId {
+		t.Errorf("Expected both pages of members, but got %+v", memberships)
+	}
+
+	if RoleViewer.CanEdit() || !RoleEditor.CanEdit() || RoleEditor.CanManage() || !RoleOwner.CanManage() {
+		t.Errorf("Expected only owners to manage and only owners and editors to edit")
+	}
+}
+
+func TestSetMembershipRole(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	ownerCount := 2
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		if OrganizationsTable == *input.TableName {
+			attr, _ := dynamodbattribute.MarshalMap(&Organization{OrgId: "org1", OwnerCount: ownerCount})
+			return &dynamodb.GetItemOutput{Item: attr}, nil
+		}
+		if "user1" != *input.Key["user_id"].S {
+			return &dynamodb.GetItemOutput{}, nil
+		}
+		attr, _ := dynamodbattribute.MarshalMap(&Membership{OrgId: "org1", UserId: "user1", Role: RoleOwner})
+		return &dynamodb.GetItemOutput{Item: attr}, nil
+	}
+
+	var transaction *dynamodb.TransactWriteItemsInput
+	var canceled []*dynamodb.CancellationReason
+	mocks.TransactWriteItemsMock = func(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+		transaction = input
+		if canceled != nil {
+			return nil, &dynamodb.TransactionCanceledException{CancellationReasons: canceled}
+		}
+		return &dynamodb.TransactWriteItemsOutput{}, nil
+	}
+
+	demote := &Membership{TenantId: "tenant1", OrgId: "org1", UserId: "user1", Role: RoleEditor}
+	if err := SetMembershipRole(context.Background(), demote, svc, logger); err != nil {
+		t.Fatalf("Failed to demote an owner when another remains: %s", err.Error())
+	}
+
+	// The membership and the owner count are written together, each conditioned on what was read
+	if len(transaction.TransactItems) != 2 {
+		t.Fatalf("Expected the membership and the organization to be written together, but there were %d items", len(transaction.TransactItems))
+	} else if put := transaction.TransactItems[0].Put; put == nil || "#role = :current" != *put.ConditionExpression || "owner" != *put.ExpressionAttributeValues[":current"].S {
+		t.Errorf("Expected the membership to be conditioned on still being an owner, but was %v", transaction.TransactItems[0])
+	} else if update := transaction.TransactItems[1].Update; update == nil || "owner_count > :one" != *update.ConditionExpression || "-1" != *update.ExpressionAttributeValues[":change"].N {
+		t.Errorf("Expected the owner count to be decremented only while another owner remains, but was %v", transaction.TransactItems[1])
+	}
+
+	canceled = []*dynamodb.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")}}
+	if err := SetMembershipRole(context.Background(), demote, svc, logger); err != ErrLastOwner {
+		t.Errorf("Expected a refused owner count to be '%s', but was %v", ErrorLastOwner, err)
+	}
+
+	canceled = []*dynamodb.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}, {Code: aws.String("None")}}
+	if err := RemoveMembership(context.Background(), &MembershipKey{TenantId: "tenant1", OrgId: "org1", UserId: "user1"}, svc, logger); err != ErrMembershipChanged {
+		t.Errorf("Expected a refused membership to be '%s', but was %v", ErrorMembershipChanged, err)
+	}
+
+	// Organizations from before the count was kept have it set from their memberships first
+	canceled, ownerCount = nil, 0
+	owners, _ := dynamodbattribute.MarshalMap(&Membership{OrgId: "org1", UserId: "user1", Role: RoleOwner})
+	mocks.QueryMock = func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		return &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{owners, owners}}, nil
+	}
+	var seeded string
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		seeded = *input.ExpressionAttributeValues[":owners"].N
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+	if err := SetMembershipRole(context.Background(), demote, svc, logger); err != nil {
+		t.Errorf("Failed to demote an owner of an uncounted organization: %s", err.Error())
+	} else if "2" != seeded {
+		t.Errorf("Expected the owner count to be set to 2, but was '%s'", seeded)
+	}
+
+	transaction = nil
+	if err := RemoveMembership(context.Background(), &MembershipKey{TenantId: "tenant1", OrgId: "org1", UserId: "user2"}, svc, logger); err != nil || transaction != nil {
+		t.Errorf("Expected removing someone who isn't a member to do nothing, but error was %v", err)
+	}
+}
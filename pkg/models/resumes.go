@@ -0,0 +1,228 @@
+package models
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorInvalidResumeId    = "invalid resume_id"
+	ErrorUnknownResumeEntry = "resume selects an entry that is not in the user's profile"
+)
+
+var ResumesTable = "resume_resume"
+
+// Resume is a named view over a user's profile. It selects which of the profile's entries to show, in the order they
+// are listed, and can replace the profile's summary. The entries themselves only live on the User, so editing the
+// profile updates every resume that selects them.
+type Resume struct {
+	TenantId       string             `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	UserId         string             `json:"user_id" yaml:"user_id" xml:"user_id"`
+	ResumeId       string             `json:"resume_id" yaml:"resume_id" xml:"resume_id"`
+	Name           string             `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	Summary        string             `json:"summary,omitempty" yaml:"summary,omitempty" xml:"summary,omitempty"`
+	Certifications []CertificationKey `json:"certifications,omitempty" yaml:"certifications,omitempty" xml:"certifications>certification,omitempty"`
+	Degrees        []DegreeKey        `json:"degrees,omitempty" yaml:"degrees,omitempty" xml:"degrees>degree,omitempty"`
+	Experience     []ExperienceKey    `json:"experience,omitempty" yaml:"experience,omitempty" xml:"experience>position,omitempty"`
+	Skills         []SkillKey         `json:"skills,omitempty" yaml:"skills,omitempty" xml:"skills>skill,omitempty"`
+}
+
+type ResumeKey struct {
+	TenantId string `json:"-"`
+	UserId   string `json:"user_id"`
+	ResumeId string `json:"resume_id"`
+}
+
+// ValidateResume checks every entry the resume selects exists in the user's profile
+func ValidateResume(resume *Resume, user *User) error {
+	if len(resume.ResumeId) == 0 {
+		return &FieldError{Field: "resume_id", Err: ErrInvalidResumeId}
+	}
+
+	if len(resume.UserId) == 0 {
+		return &FieldError{Field: "user_id", Err: ErrInvalidUserId}
+	}
+
+	view := ApplyResume(resume, user)
+	if len(view.Certifications) != len(resume.Certifications) {
+		return &FieldError{Field: "certifications", Err: ErrUnknownResumeEntry}
+	}
+
+	if len(view.Degrees) != len(resume.Degrees) {
+		return &FieldError{Field: "degrees", Err: ErrUnknownResumeEntry}
+	}
+
+	if len(view.Experience) != len(resume.Experience) {
+		return &FieldError{Field: "experience", Err: ErrUnknownResumeEntry}
+	}
+
+	if len(view.Skills) != len(resume.Skills) {
+		return &FieldError{Field: "skills", Err: ErrUnknownResumeEntry}
+	}
+
+	return nil
+}
+
+// ApplyResume builds the user the resume describes. Selected entries that have since been removed from the profile are
+// left out rather than failing, so deleting an entry never breaks a resume.
+func ApplyResume(resume *Resume, user *User) *User {
+	view := *user
+	view.Summary = firstNonEmpty(resume.Summary, user.Summary)
+
+	certifications := make(map[CertificationKey]Certification)
+	for _, cert := range user.Certifications {
+		certifications[cert.Key()] = cert
+	}
+	view.Certifications = nil
+	for _, key := range resume.Certifications {
+		if cert, ok := certifications[key]; ok {
+			view.Certifications = append(view.Certifications, cert)
+		}
+	}
+
+	degrees := make(map[DegreeKey]Degree)
+	for _, degree := range user.Degrees {
+		degrees[degree.Key()] = degree
+	}
+	view.Degrees = nil
+	for _, key := range resume.Degrees {
+		if degree, ok := degrees[key]; ok {
+			view.Degrees = append(view.Degrees, degree)
+		}
+	}
+
+	experience := make(map[ExperienceKey]Experience)
+	for _, exp := range user.Experience {
+		experience[exp.Key()] = exp
+	}
+	view.Experience = nil
+	for _, key := range resume.Experience {
+		if exp, ok := experience[key]; ok {
+			view.Experience = append(view.Experience, exp)
+		}
+	}
+
+	skills := make(map[SkillKey]Skill)
+	for _, skill := range user.Skills {
+		skills[skill.Key()] = skill
+	}
+	view.Skills = nil
+	for _, key := range resume.Skills {
+		if skill, ok := skills[key]; ok {
+			view.Skills = append(view.Skills, skill)
+		}
+	}
+
+	return &view
+}
+
+// PutResume stores the resume. It must already have been validated against the user's profile with ValidateResume.
+func PutResume(ctx context.Context, resume *Resume, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if len(resume.ResumeId) == 0 {
+		logger.Error("ResumeId is empty")
+		return &FieldError{Field: "resume_id", Err: ErrInvalidResumeId}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(resume)
+	if err == nil {
+		err = tenantItem(item, resume.TenantId, resume.UserId)
+	}
+	if err == nil {
+		err = checkItemSize(item)
+	}
+	if err != nil {
+		logger.Error("Failed to construct input for put resume", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(ResumesTable),
+	})
+	if err != nil {
+		logger.Error("Failed to insert resume into database", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully inserted resume into database", zap.String("user_id", resume.UserId), zap.String("resume_id", resume.ResumeId))
+	return nil
+}
+
+func GetResume(ctx context.Context, key *ResumeKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*Resume, error) {
+	keyAttr, err := resumeKey(key)
+	if err != nil {
+		logger.Error("Failed to get input to get resume", zap.Error(err))
+		return nil, err
+	}
+
+	resume := &Resume{}
+	if err := getItem(ctx, ResumesTable, keyAttr, resume, svc, logger); err != nil {
+		return nil, err
+	}
+
+	return resume, nil
+}
+
+// GetResumes lists all of the user's resumes
+func GetResumes(ctx context.Context, key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) ([]Resume, error) {
+	pk, err := tenantKey(key.TenantId, key.UserId)
+	if err != nil {
+		logger.Error("Failed to get input to query resumes", zap.Error(err))
+		return nil, err
+	}
+
+	items, err := queryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(ResumesTable),
+		KeyConditionExpression:    aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":pk": pk},
+	}, svc)
+	if err != nil {
+		logger.Error("Failed to query resumes", zap.Error(err), zap.String("user_id", key.UserId))
+		return nil, err
+	}
+
+	var resumes []Resume
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &resumes); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes to Resume objects", zap.Error(err))
+		return nil, err
+	}
+
+	return resumes, nil
+}
+
+func DeleteResume(ctx context.Context, key *ResumeKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	keyAttr, err := resumeKey(key)
+	if err != nil {
+		logger.Error("Failed to get input to delete resume", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		Key:       keyAttr,
+		TableName: aws.String(ResumesTable),
+	})
+	if err != nil {
+		logger.Error("Failed to delete resume", zap.Error(err), zap.String("user_id", key.UserId), zap.String("resume_id", key.ResumeId))
+		return err
+	}
+
+	logger.Info("Deleted resume", zap.String("user_id", key.UserId), zap.String("resume_id", key.ResumeId))
+	return nil
+}
+
+// resumeKey partitions resumes by the user within the tenant, sorted by resume
+func resumeKey(key *ResumeKey) (map[string]*dynamodb.AttributeValue, error) {
+	pk, err := tenantKey(key.TenantId, key.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]*dynamodb.AttributeValue{
+		PartitionKey: pk,
+		"resume_id":  {S: aws.String(key.ResumeId)},
+	}, nil
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+func TestApplyResume(t *testing.T) {
+	setup(t)
+	user.Experience = append(user.Experience, Experience{Company: "Other Co", JobTitle: "Backend Engineer"})
+
+	resume := &Resume{
+		UserId:     "user1",
+		ResumeId:   "backend",
+		Summary:    "Backend summary",
+		Experience: []ExperienceKey{{Company: "Other Co", JobTitle: "Backend Engineer"}, {Company: "Co", JobTitle: "SRE"}},
+		Skills:     []SkillKey{{Name: "Go"}},
+	}
+
+	view := ApplyResume(resume, user)
+	if "Backend summary" != view.Summary {
+		t.Errorf("Expected summary to be overridden, but was '%s'", view.Summary)
+	}
+
+	if len(view.Experience) != 2 || "Other Co" != view.Experience[0].Company {
+		t.Errorf("Expected experience in the order the resume selects it, but was %+v", view.Experience)
+	}
+
+	if len(view.Degrees) != 0 || len(view.Certifications) != 0 {
+		t.Errorf("Expected unselected entries to be left out")
+	}
+
+	if len(user.Experience) != 2 || "My awesome summary" != user.Summary {
+		t.Errorf("Expected the profile to be left unchanged")
+	}
+
+	resume.Summary = ""
+	if view := ApplyResume(resume, user); user.Summary != view.Summary {
+		t.Errorf("Expected summary to fall back to the profile's, but was '%s'", view.Summary)
+	}
+}
+
+func TestValidateResume(t *testing.T) {
+	setup(t)
+
+	resume := &Resume{UserId: "user1", ResumeId: "sre", Skills: []SkillKey{{Name: "Go"}}}
+	if err := ValidateResume(resume, user); err != nil {
+		t.Errorf("Expected resume to be valid, but got '%s'", err.Error())
+	}
+
+	resume.Skills = append(resume.Skills, SkillKey{Name: "Rust"})
+	if err := ValidateResume(resume, user); err == nil {
+		t.Errorf("Expected a resume selecting a missing skill to be invalid")
+	} else if fieldErr, ok := err.(*FieldError); !ok || "skills" != fieldErr.Field || ErrorUnknownResumeEntry != err.Error() {
+		t.Errorf("Expected error to be '%s' on skills, but was '%s'", ErrorUnknownResumeEntry, err.Error())
+	}
+}
+
+func TestGetResume(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		if ResumesTable != *input.TableName {
+			t.Errorf("Expected table name to be '%s', but was '%s'", ResumesTable, *input.TableName)
+		}
+		if "tenant1#user1" != *input.Key[PartitionKey].S || "sre" != *input.Key["resume_id"].S {
+			t.Errorf("Expected resume to be keyed by the user in the tenant, but was %+v", input.Key)
+		}
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	if _, err := GetResume(context.Background(), &ResumeKey{TenantId: "tenant1", UserId: "user1", ResumeId: "sre"}, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorNoResultsFound != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorNoResultsFound, err.Error())
+	}
+}
+
+func TestGetResumes(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	pages := [][]Resume{
+		{{UserId: "user1", ResumeId: "dev"}, {UserId: "user1", ResumeId: "sre"}},
+		{{UserId: "user1", ResumeId: "ops"}},
+	}
+	mocks.QueryMock = func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		if ResumesTable != *input.TableName || "tenant1#user1" != *input.ExpressionAttributeValues[":pk"].S {
+			t.Errorf("Expected query to be for the user's resumes in the tenant, but was %+v", input)
+		}
+
+		page := 0
+		if input.ExclusiveStartKey != nil {
+			page = 1
+		}
+
+		output := &dynamodb.QueryOutput{}
+		for _, resume := range pages[page] {
+			attr, _ := dynamodbattribute.MarshalMap(resume)
+			output.Items = append(output.Items, attr)
+		}
+		if page == 0 {
+			output.LastEvaluatedKey = output.Items[len(output.Items)-1]
+		}
+		return output, nil
+	}
+
+	if resumes, err := GetResumes(context.Background(), &UserKey{TenantId: "tenant1", UserId: "user1"}, svc, logger); err != nil {
+		t.Errorf("Failed to get resumes: %s", err.Error())
+	} else if len(resumes) != 3 || "ops" != resumes[2].ResumeId {
+		t.Errorf("Expected resumes from both pages, but got %+v", resumes)
+	}
+}
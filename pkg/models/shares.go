@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorInvalidShareId   = "invalid share_id"
+	ErrorShareUnavailable = "share link has expired or been revoked"
+)
+
+var SharesTable = "resume_share"
+
+// Share is a read-only link to a user's resume. ExpiresAt is a unix timestamp so the table can use it for TTL, and a
+// MaxViews of 0 means the link is only limited by time. Shares are looked up by their random ID before the tenant is
+// known, so their key isn't tenant prefixed, and the TenantId they record scopes the resume they show.
+type Share struct {
+	TenantId  string `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	ShareId   string `json:"share_id" yaml:"share_id" xml:"share_id"`
+	UserId    string `json:"user_id" yaml:"user_id" xml:"user_id"`
+	CreatedAt int64  `json:"created_at" yaml:"created_at" xml:"created_at"`
+	ExpiresAt int64  `json:"expires_at" yaml:"expires_at" xml:"expires_at"`
+	MaxViews  int    `json:"max_views,omitempty" yaml:"max_views,omitempty" xml:"max_views,omitempty"`
+	Views     int    `json:"views" yaml:"views" xml:"views"`
+	Revoked   bool   `json:"revoked" yaml:"revoked" xml:"revoked"`
+}
+
+type ShareKey struct {
+	TenantId string `json:"-"`
+	ShareId  string `json:"share_id"`
+}
+
+func PutShare(ctx context.Context, share *Share, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if err := validateTenantId(share.TenantId); err != nil {
+		logger.Error("TenantId is invalid", zap.String("tenant_id", share.TenantId))
+		return err
+	}
+
+	if len(share.ShareId) == 0 {
+		logger.Error("ShareId is empty")
+		return &FieldError{Field: "share_id", Err: ErrInvalidShareId}
+	}
+
+	if len(share.UserId) == 0 {
+		logger.Error("UserId is empty")
+		return &FieldError{Field: "user_id", Err: ErrInvalidUserId}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(share)
+	if err != nil {
+		logger.Error("Failed to construct input for create share", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(SharesTable),
+	})
+	if err != nil {
+		logger.Error("Failed to insert new share into database", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully inserted new share into database", zap.String("share_id", share.ShareId))
+	return nil
+}
+
+// RecordShareView counts a view against the share and returns it, but only while the share belongs to userId and is
+// unrevoked, unexpired and under its view limit. The checks happen in the same conditional write as the increment so
+// concurrent views can't exceed the limit.
+func RecordShareView(ctx context.Context, key *ShareKey, userId string, now time.Time, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*Share, error) {
+	input, err := getShareViewInput(key, userId, now)
+	if err != nil {
+		logger.Error("Failed to get input to record share view", zap.Error(err))
+		return nil, err
+	}
+
+	result, err := svc.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			logger.Warn("Share is no longer available", zap.String("share_id", key.ShareId))
+			return nil, ErrShareUnavailable
+		}
+
+		logger.Error("Failed to record share view", zap.Error(err), zap.String("share_id", key.ShareId))
+		return nil, err
+	}
+
+	share := &Share{}
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, share); err != nil {
+		logger.Error("Failed to unmarshall dynamo attributes to Share object", zap.Error(err))
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// UndoShareView gives back a view counted by RecordShareView when the resume couldn't be returned for it
+func UndoShareView(ctx context.Context, key *ShareKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		logger.Error("Failed to get input to undo share view", zap.Error(err))
+		return err
+	}
+
+	_, err = svc.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		Key:                      keyAttr,
+		TableName:                aws.String(SharesTable),
+		UpdateExpression:         aws.String("ADD #views :minus"),
+		ConditionExpression:      aws.String("#views > :zero"),
+		ExpressionAttributeNames: map[string]*string{"#views": aws.String("views")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":minus": {N: aws.String("-1")},
+			":zero":  {N: aws.String("0")},
+		},
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		logger.Error("Failed to undo share view", zap.Error(err), zap.String("share_id", key.ShareId))
+		return err
+	}
+
+	return nil
+}
+
+func getShareViewInput(key *ShareKey, userId string, now time.Time) (*dynamodb.UpdateItemInput, error) {
+	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.UpdateItemInput{
+		Key:                 keyAttr,
+		TableName:           aws.String(SharesTable),
+		UpdateExpression:    aws.String("ADD #views :one"),
+		ConditionExpression: aws.String("attribute_exists(share_id) AND user_id = :uid AND #revoked = :false AND expires_at > :now AND (max_views = :zero OR attribute_not_exists(max_views) OR #views < max_views)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#revoked": aws.String("revoked"),
+			"#views":   aws.String("views"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":false": {BOOL: aws.Bool(false)},
+			":now":   {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+			":one":   {N: aws.String("1")},
+			":uid":   {S: aws.String(userId)},
+			":zero":  {N: aws.String("0")},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	}, nil
+}
+
+// RevokeShare marks the share as revoked as long as it belongs to userId in the key's tenant, otherwise it is treated as
+// not found
+func RevokeShare(ctx context.Context, key *ShareKey, userId string, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	input, err := getShareRevokeInput(key, userId)
+	if err != nil {
+		logger.Error("Failed to get input to revoke share", zap.Error(err))
+		return err
+	}
+
+	if _, err := svc.UpdateItemWithContext(ctx, input); err != nil {
+		if isConditionalCheckFailed(err) {
+			logger.Warn("No share found for user", zap.String("share_id", key.ShareId), zap.String("user_id", userId))
+			return ErrNoResultsFound
+		}
+
+		logger.Error("Failed to revoke share", zap.Error(err), zap.String("share_id", key.ShareId))
+		return err
+	}
+
+	logger.Info("Revoked share", zap.String("share_id", key.ShareId), zap.String("user_id", userId))
+	return nil
+}
+
+func getShareRevokeInput(key *ShareKey, userId string) (*dynamodb.UpdateItemInput, error) {
+	if err := validateTenantId(key.TenantId); err != nil {
+		return nil, err
+	}
+
+	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.UpdateItemInput{
+		Key:                 keyAttr,
+		TableName:           aws.String(SharesTable),
+		UpdateExpression:    aws.String("SET #revoked = :true"),
+		ConditionExpression: aws.String("tenant_id = :tenant_id AND user_id = :user_id"),
+		ExpressionAttributeNames: map[string]*string{
+			"#revoked": aws.String("revoked"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":tenant_id": {S: aws.String(key.TenantId)},
+			":true":      {BOOL: aws.Bool(true)},
+			":user_id":   {S: aws.String(userId)},
+		},
+	}, nil
+}
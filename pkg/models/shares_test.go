@@ -0,0 +1,106 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+func TestPutShare(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	share := &Share{TenantId: "tenant1", ShareId: "share1", UserId: "user1", ExpiresAt: 1600000000}
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		if SharesTable != *input.TableName {
+			t.Errorf("Expected table name to be '%s', but was '%s'", SharesTable, *input.TableName)
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if err := PutShare(context.Background(), share, svc, logger); err != nil {
+		t.Errorf("Failed to create share when it should have been successful: %s", err.Error())
+	}
+
+	if err := PutShare(context.Background(), &Share{TenantId: "tenant1", UserId: "user1"}, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorInvalidShareId != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidShareId, err.Error())
+	}
+}
+
+func TestRecordShareView(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	key := &ShareKey{ShareId: "share1"}
+	now := time.Unix(1600000000, 0)
+	attr, _ := dynamodbattribute.MarshalMap(&Share{ShareId: "share1", UserId: "user1", Views: 1})
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		if "1600000000" != *input.ExpressionAttributeValues[":now"].N {
+			t.Errorf("Expected the condition to compare against now")
+		}
+		if "user1" != *input.ExpressionAttributeValues[":uid"].S {
+			t.Errorf("Expected the condition to check the share belongs to the token's user")
+		}
+		return &dynamodb.UpdateItemOutput{Attributes: attr}, nil
+	}
+	if share, err := RecordShareView(context.Background(), key, "user1", now, svc, logger); err != nil {
+		t.Errorf("Failed to record view: %s", err.Error())
+	} else if "user1" != share.UserId || 1 != share.Views {
+		t.Errorf("Expected updated share to be returned, but got %+v", share)
+	}
+
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	if _, err := RecordShareView(context.Background(), key, "user1", now, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorShareUnavailable != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorShareUnavailable, err.Error())
+	}
+
+	expectedError := "some error"
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return nil, fmt.Errorf(expectedError)
+	}
+	if _, err := RecordShareView(context.Background(), key, "user1", now, svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if expectedError != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", expectedError, err.Error())
+	}
+}
+
+func TestRevokeShare(t *testing.T) {
+	setup(t)
+
+	svc := mocks.DynamoServiceMock{}
+	key := &ShareKey{TenantId: "tenant1", ShareId: "share1"}
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		if "user1" != aws.StringValue(input.ExpressionAttributeValues[":user_id"].S) {
+			t.Errorf("Expected revoke to be conditional on the owning user")
+		}
+		if "tenant1" != aws.StringValue(input.ExpressionAttributeValues[":tenant_id"].S) {
+			t.Errorf("Expected revoke to be conditional on the tenant")
+		}
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+	if err := RevokeShare(context.Background(), key, "user1", svc, logger); err != nil {
+		t.Errorf("Failed to revoke share: %s", err.Error())
+	}
+
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	if err := RevokeShare(context.Background(), key, "user1", svc, logger); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorNoResultsFound != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorNoResultsFound, err.Error())
+	}
+}
@@ -1,10 +1,10 @@
 package models
 
 type Skill struct {
-	Name              string `json:"name"`
-	YearsOfExperience int    `json:"years_of_experience,omitempty"`
+	Name              string `json:"name" yaml:"name" xml:"name"`
+	YearsOfExperience int    `json:"years_of_experience,omitempty" yaml:"years_of_experience,omitempty" xml:"years_of_experience,omitempty"`
 }
 
 type SkillKey struct {
-	Name string `json:"name"`
+	Name string `json:"name" yaml:"name" xml:"name"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// DefaultTenant holds the data of callers who don't belong to a tenant, including anonymous readers
+	DefaultTenant = "default"
+
+	ErrorInvalidTenantId = "invalid tenant_id"
+
+	// PartitionKey is the hash key of every tenant scoped table. Its value is always built by tenantKey.
+	PartitionKey = "pk"
+
+	tenantSeparator = "#"
+)
+
+// tenantKey prefixes id with the tenant it belongs to. Every key for a tenant scoped table is built here, so whatever id
+// a handler passes, it can only reach items in the tenant it was given.
+func tenantKey(tenantId string, id string) (*dynamodb.AttributeValue, error) {
+	if err := validateTenantId(tenantId); err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.AttributeValue{S: aws.String(tenantId + tenantSeparator + id)}, nil
+}
+
+// tenantItem adds the partition key to an item about to be written
+func tenantItem(item map[string]*dynamodb.AttributeValue, tenantId string, id string) error {
+	pk, err := tenantKey(tenantId, id)
+	if err != nil {
+		return err
+	}
+
+	item[PartitionKey] = pk
+	return nil
+}
+
+// validateTenantId rejects an empty tenant rather than letting it fall back to any default, so a caller that forgot to
+// set the tenant fails instead of reading another tenant's data
+func validateTenantId(tenantId string) error {
+	if len(tenantId) == 0 || strings.Contains(tenantId, tenantSeparator) {
+		return &FieldError{Field: "tenant_id", Err: ErrInvalidTenantId}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+)
+
+// storeByPartitionKey makes the mock behave like a table keyed on pk, so reads only find what was written under the
+// same key
+func storeByPartitionKey() {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		items[*input.Item[PartitionKey].S] = input.Item
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return &dynamodb.GetItemOutput{Item: items[*input.Key[PartitionKey].S]}, nil
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	setup(t)
+	storeByPartitionKey()
+
+	svc := mocks.DynamoServiceMock{}
+	if err := PutUser(context.Background(), user, svc, logger); err != nil {
+		t.Fatalf("Failed to create user when it should have been successful: %s", err.Error())
+	}
+
+	if found, err := GetUserByKey(context.Background(), &UserKey{TenantId: "tenant1", UserId: "user1"}, svc, logger); err != nil {
+		t.Errorf("Expected to get the user in its own tenant, but got the error '%s'", err.Error())
+	} else if "tenant1" != found.TenantId {
+		t.Errorf("Expected tenant to be 'tenant1', but was '%s'", found.TenantId)
+	}
+
+	if _, err := GetUserByKey(context.Background(), &UserKey{TenantId: "tenant2", UserId: "user1"}, svc, logger); err == nil {
+		t.Errorf("Expected a user to be invisible from another tenant")
+	} else if ErrorNoResultsFound != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorNoResultsFound, err.Error())
+	}
+
+	for _, tenantId := range []string{"", "tenant1#user1"} {
+		if _, err := GetUserByKey(context.Background(), &UserKey{TenantId: tenantId, UserId: "user1"}, svc, logger); err == nil {
+			t.Errorf("Expected tenant '%s' to be rejected", tenantId)
+		} else if ErrorInvalidTenantId != err.Error() {
+			t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidTenantId, err.Error())
+		}
+	}
+}
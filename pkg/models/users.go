@@ -1,7 +1,7 @@
 package models
 
 import (
-	"errors"
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
@@ -14,38 +14,63 @@ const (
 	ErrorInvalidEmail   = "invalid email"
 	ErrorInvalidUserId  = "invalid user_id"
 	ErrorNoResultsFound = "no results found"
-	UsersTable          = "resume_user"
 )
 
+var UsersTable = "resume_user"
+
+// User is a resume. TenantId is never read from or written to a request body, the handlers set it from the caller.
 type User struct {
-	UserId         string          `json:"user_id"`
-	Email          string          `json:"email"`
-	Certifications []Certification `json:"certifications,omitempty"`
-	Degrees        []Degree        `json:"degrees,omitempty"`
-	Experience     []Experience    `json:"experience,omitempty"`
-	Github         string          `json:"github,omitempty"`
-	GivenName      string          `json:"given_name,omitempty"`
-	Location       string          `json:"location,omitempty"`
-	Linkedin       string          `json:"linkedin,omitempty"`
-	PhoneNumber    string          `json:"phone_number,omitempty"`
-	Skills         []Skill         `json:"skills,omitempty"`
-	Summary        string          `json:"summary,omitempty"`
-	SurName        string          `json:"sur_name,omitempty"`
+	TenantId       string              `json:"-" yaml:"-" xml:"-" dynamodbav:"tenant_id"`
+	UserId         string              `json:"user_id" yaml:"user_id" xml:"user_id"`
+	OrgId          string              `json:"org_id,omitempty" yaml:"org_id,omitempty" xml:"org_id,omitempty"`
+	Email          string              `json:"email" yaml:"email" xml:"email"`
+	Certifications []Certification     `json:"certifications,omitempty" yaml:"certifications,omitempty" xml:"certifications>certification,omitempty"`
+	Degrees        []Degree            `json:"degrees,omitempty" yaml:"degrees,omitempty" xml:"degrees>degree,omitempty"`
+	Experience     []Experience        `json:"experience,omitempty" yaml:"experience,omitempty" xml:"experience>position,omitempty"`
+	Github         string              `json:"github,omitempty" yaml:"github,omitempty" xml:"github,omitempty"`
+	GivenName      string              `json:"given_name,omitempty" yaml:"given_name,omitempty" xml:"given_name,omitempty"`
+	Location       string              `json:"location,omitempty" yaml:"location,omitempty" xml:"location,omitempty"`
+	Linkedin       string              `json:"linkedin,omitempty" yaml:"linkedin,omitempty" xml:"linkedin,omitempty"`
+	PhoneNumber    string              `json:"phone_number,omitempty" yaml:"phone_number,omitempty" xml:"phone_number,omitempty"`
+	Skills         []Skill             `json:"skills,omitempty" yaml:"skills,omitempty" xml:"skills>skill,omitempty"`
+	Summary        string              `json:"summary,omitempty" yaml:"summary,omitempty" xml:"summary,omitempty"`
+	SurName        string              `json:"sur_name,omitempty" yaml:"sur_name,omitempty" xml:"sur_name,omitempty"`
+	Visibility     *VisibilitySettings `json:"visibility,omitempty" yaml:"visibility,omitempty" xml:"-"`
 }
 
 type UserKey struct {
-	UserId string `json:"user_id"`
+	TenantId string `json:"-" yaml:"-" xml:"-"`
+	UserId   string `json:"user_id" yaml:"user_id" xml:"user_id"`
 }
 
-func PutUser(user *User, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
-	if !isEmail(user.Email) {
-		logger.Error("Email is not a valid email", zap.String("email", user.Email))
-		return errors.New(ErrorInvalidEmail)
-	}
+// FieldError is a validation failure tied to the field that caused it. Line is only known when the user was decoded
+// from YAML.
+type FieldError struct {
+	Field string
+	Line  int
+	Code  string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateUser checks the user and every entry in it, returning a ValidationError listing all of the violations
+func ValidateUser(user *User) error {
+	v := &validator{}
+	v.validateUser(user)
+	return v.err()
+}
 
-	if len(user.UserId) == 0 {
-		logger.Error("UserId is empty")
-		return errors.New(ErrorInvalidUserId)
+func PutUser(ctx context.Context, user *User, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+	if err := ValidateUser(user); err != nil {
+		logger.Error("User is not valid", zap.Error(err), zap.String("user_id", user.UserId), zap.String("email", user.Email))
+		return err
 	}
 
 	input, err := getUserPutInput(user)
@@ -54,7 +79,7 @@ func PutUser(user *User, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) erro
 		return err
 	}
 
-	_, err = svc.PutItem(input)
+	_, err = svc.PutItemWithContext(ctx, input)
 	if err != nil {
 		logger.Error("Failed to insert new user into database", zap.Error(err))
 		return err
@@ -75,6 +100,14 @@ func getUserPutInput(user *User) (*dynamodb.PutItemInput, error) {
 		return nil, err
 	}
 
+	if err := tenantItem(item, user.TenantId, user.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := checkItemSize(item); err != nil {
+		return nil, err
+	}
+
 	input := &dynamodb.PutItemInput{
 		Item:      item,
 		TableName: aws.String(UsersTable),
@@ -82,14 +115,14 @@ func getUserPutInput(user *User) (*dynamodb.PutItemInput, error) {
 	return input, nil
 }
 
-func GetUserByKey(key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*User, error) {
+func GetUserByKey(ctx context.Context, key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) (*User, error) {
 	input, err := getUserGetItemInput(key)
 	if err != nil {
 		logger.Error("Failed to get input to query user table for ID", zap.Error(err))
 		return nil, err
 	}
 
-	result, err := svc.GetItem(input)
+	result, err := svc.GetItemWithContext(ctx, input)
 	if err != nil {
 		logger.Error("Failed to get user with key", zap.Error(err), zap.String("user_id", key.UserId))
 		return nil, err
@@ -97,7 +130,7 @@ func GetUserByKey(key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logge
 
 	if len(result.Item) == 0 {
 		logger.Error("No results found for with key", zap.String("user_id", key.UserId))
-		return nil, errors.New(ErrorNoResultsFound)
+		return nil, ErrNoResultsFound
 	}
 
 	logger.Info("Found user with key", zap.String("user_id", key.UserId))
@@ -113,27 +146,27 @@ func GetUserByKey(key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logge
 }
 
 func getUserGetItemInput(key *UserKey) (*dynamodb.GetItemInput, error) {
-	keyAttr, err := dynamodbattribute.MarshalMap(key)
+	pk, err := tenantKey(key.TenantId, key.UserId)
 	if err != nil {
 		return nil, err
 	}
 
 	input := &dynamodb.GetItemInput{
-		Key:       keyAttr,
+		Key:       map[string]*dynamodb.AttributeValue{PartitionKey: pk},
 		TableName: aws.String(UsersTable),
 	}
 
 	return input, nil
 }
 
-func DeleteUser(key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
+func DeleteUser(ctx context.Context, key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger) error {
 	input, err := getUserDeleteInput(key)
 	if err != nil {
 		logger.Error("Failed to get delete input", zap.Error(err))
 		return err
 	}
 
-	_, err = svc.DeleteItem(input)
+	_, err = svc.DeleteItemWithContext(ctx, input)
 	if err != nil {
 		logger.Error("Failed to delete user from database", zap.Error(err))
 		return err
@@ -143,13 +176,13 @@ func DeleteUser(key *UserKey, svc dynamodbiface.DynamoDBAPI, logger *zap.Logger)
 }
 
 func getUserDeleteInput(keyObj *UserKey) (*dynamodb.DeleteItemInput, error) {
-	key, err := dynamodbattribute.MarshalMap(keyObj)
+	pk, err := tenantKey(keyObj.TenantId, keyObj.UserId)
 	if err != nil {
 		return nil, err
 	}
 
 	input := &dynamodb.DeleteItemInput{
-		Key:       key,
+		Key:       map[string]*dynamodb.AttributeValue{PartitionKey: pk},
 		TableName: aws.String(UsersTable),
 	}
 	return input, nil
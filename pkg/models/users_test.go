@@ -1,10 +1,15 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	mocks "github.com/bkimbrough88/resume-backend/pkg"
@@ -23,8 +28,8 @@ func setup(t *testing.T) {
 			{
 				Name:         "Some Cert",
 				BadgeLink:    "https://example.com",
-				DateAchieved: "10-28-2019",
-				DateExpires:  "10-28-2022",
+				DateAchieved: Date{Year: 2019, Month: time.October, Day: 28},
+				DateExpires:  &Date{Year: 2022, Month: time.October, Day: 28},
 			},
 		},
 		Degrees: []Degree{
@@ -39,12 +44,10 @@ func setup(t *testing.T) {
 		Email: "user@domain.com",
 		Experience: []Experience{
 			{
-				Company:    "Co",
-				JobTitle:   "SRE",
-				StartMonth: "May",
-				StartYear:  2020,
-				EndMonth:   "June",
-				EndYear:    2020,
+				Company:   "Co",
+				JobTitle:  "SRE",
+				StartDate: Date{Year: 2020, Month: time.May},
+				EndDate:   &Date{Year: 2020, Month: time.June},
 				Responsibilities: []string{
 					"foo",
 					"bar",
@@ -55,16 +58,17 @@ func setup(t *testing.T) {
 		GivenName:   "John",
 		Location:    "Place, State",
 		Linkedin:    "https://www.linkedin.com/in/user",
-		PhoneNumber: "999-999-9999",
+		PhoneNumber: "+19999999999",
 		Skills: []Skill{
 			{
 				Name:              "Go",
 				YearsOfExperience: 2,
 			},
 		},
-		Summary: "My awesome summary",
-		SurName: "Doe",
-		UserId:  "user1",
+		Summary:  "My awesome summary",
+		SurName:  "Doe",
+		TenantId: "tenant1",
+		UserId:   "user1",
 	}
 
 	mocks.DeleteItemMock = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
@@ -92,7 +96,7 @@ func TestCreateUser(t *testing.T) {
 	setup(t)
 
 	svc := mocks.DynamoServiceMock{}
-	if err := PutUser(user, svc, logger); err != nil {
+	if err := PutUser(context.Background(), user, svc, logger); err != nil {
 		t.Errorf("Failed to create user when it should have been successful: %s", err.Error())
 	}
 
@@ -100,7 +104,7 @@ func TestCreateUser(t *testing.T) {
 		UserId: "user",
 		Email:  "not an email",
 	}
-	if err := PutUser(userBadEmail, svc, logger); err == nil {
+	if err := PutUser(context.Background(), userBadEmail, svc, logger); err == nil {
 		t.Errorf("Expected to get an error and no err was returned")
 	} else if ErrorInvalidEmail != err.Error() {
 		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidEmail, err.Error())
@@ -110,7 +114,7 @@ func TestCreateUser(t *testing.T) {
 		UserId: "",
 		Email:  "user@domain.com",
 	}
-	if err := PutUser(userBadUserId, svc, logger); err == nil {
+	if err := PutUser(context.Background(), userBadUserId, svc, logger); err == nil {
 		t.Errorf("Expected to get an error and no err was returned")
 	} else if ErrorInvalidUserId != err.Error() {
 		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidUserId, err.Error())
@@ -120,7 +124,7 @@ func TestCreateUser(t *testing.T) {
 	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
 		return nil, fmt.Errorf(expectedError)
 	}
-	if err := PutUser(user, svc, logger); err == nil {
+	if err := PutUser(context.Background(), user, svc, logger); err == nil {
 		t.Errorf("Created user when it should have failed")
 	} else if err.Error() != expectedError {
 		t.Errorf("Expected error to be '%s', but was '%s'", expectedError, err.Error())
@@ -141,10 +145,24 @@ func TestGetUserPutInput(t *testing.T) {
 
 		if input.Item == nil {
 			t.Error("User should not have generated an empty map")
+		} else if "tenant1#user1" != *input.Item[PartitionKey].S {
+			t.Errorf("Expected pk to be 'tenant1#user1', but was '%s'", *input.Item[PartitionKey].S)
 		}
 	}
 }
 
+func TestGetUserPutInputTooLarge(t *testing.T) {
+	setup(t)
+	user.Summary = strings.Repeat("a", MaxItemBytes)
+
+	var fieldErr *FieldError
+	if _, err := getUserPutInput(user); !errors.Is(err, ErrItemTooLarge) {
+		t.Errorf("Expected error to be '%s', but was '%v'", ErrorItemTooLarge, err)
+	} else if !errors.As(err, &fieldErr) || "summary" != fieldErr.Field {
+		t.Errorf("Expected the error to name 'summary' as the largest field, but was %v", err)
+	}
+}
+
 func TestIsEmail(t *testing.T) {
 	nonEmail1 := ""
 	nonEmail2 := "a@b"
@@ -176,9 +194,9 @@ func TestIsEmail(t *testing.T) {
 func TestGetUserByKey(t *testing.T) {
 	setup(t)
 
-	key := UserKey{UserId: "username"}
+	key := UserKey{TenantId: "tenant1", UserId: "username"}
 	svc := mocks.DynamoServiceMock{}
-	if res, err := GetUserByKey(&key, svc, logger); err != nil {
+	if res, err := GetUserByKey(context.Background(), &key, svc, logger); err != nil {
 		t.Errorf("Expected to get a user and got the error '%s' instead", err.Error())
 	} else if res == nil {
 		t.Errorf("Expected to get a user, but got nil")
@@ -194,7 +212,7 @@ func TestGetUserByKey(t *testing.T) {
 			Item: map[string]*dynamodb.AttributeValue{},
 		}, nil
 	}
-	if _, err := GetUserByKey(&key, svc, logger); err == nil {
+	if _, err := GetUserByKey(context.Background(), &key, svc, logger); err == nil {
 		t.Errorf("Found user when none should have been found")
 	} else if err.Error() != "no results found" {
 		t.Errorf("Expected error to be 'no results found', but was '%s'", err.Error())
@@ -204,15 +222,23 @@ func TestGetUserByKey(t *testing.T) {
 	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
 		return nil, fmt.Errorf(expectedError)
 	}
-	if _, err := GetUserByKey(&key, svc, logger); err == nil {
+	if _, err := GetUserByKey(context.Background(), &key, svc, logger); err == nil {
 		t.Errorf("Found user when it should have failed")
 	} else if err.Error() != expectedError {
 		t.Errorf("Expected error to be '%s', but was '%s'", expectedError, err.Error())
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetUserByKey(ctx, &key, svc, logger); err == nil {
+		t.Errorf("Found user when the context was already cancelled")
+	} else if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != request.CanceledErrorCode {
+		t.Errorf("Expected the request to be cancelled, but the error was '%s'", err.Error())
+	}
 }
 
 func TestGetUserGetItemInput(t *testing.T) {
-	key := &UserKey{UserId: "username"}
+	key := &UserKey{TenantId: "tenant1", UserId: "username"}
 	if input, err := getUserGetItemInput(key); err != nil {
 		t.Errorf("Failed to get input with error '%s'", err.Error())
 	} else {
@@ -224,10 +250,10 @@ func TestGetUserGetItemInput(t *testing.T) {
 
 		if input.Key == nil {
 			t.Error("User key should not have generated an empty map")
-		} else if input.Key["user_id"].S == nil {
-			t.Error("Expected user_id to be a string type")
-		} else if *input.Key["user_id"].S != key.UserId {
-			t.Errorf("Expected user_id to be '%s', but was '%s'", key.UserId, *input.Key["user_id"].S)
+		} else if input.Key[PartitionKey].S == nil {
+			t.Error("Expected pk to be a string type")
+		} else if *input.Key[PartitionKey].S != "tenant1#username" {
+			t.Errorf("Expected pk to be 'tenant1#username', but was '%s'", *input.Key[PartitionKey].S)
 		}
 	}
 }
@@ -235,9 +261,9 @@ func TestGetUserGetItemInput(t *testing.T) {
 func TestDeleteUser(t *testing.T) {
 	setup(t)
 
-	key := &UserKey{UserId: "username"}
+	key := &UserKey{TenantId: "tenant1", UserId: "username"}
 	svc := mocks.DynamoServiceMock{}
-	if err := DeleteUser(key, svc, logger); err != nil {
+	if err := DeleteUser(context.Background(), key, svc, logger); err != nil {
 		t.Errorf("Failed to delete user when it should have been successful: %s", err.Error())
 	}
 
@@ -245,7 +271,7 @@ func TestDeleteUser(t *testing.T) {
 	mocks.DeleteItemMock = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 		return nil, fmt.Errorf(expectedError)
 	}
-	if err := DeleteUser(key, svc, logger); err == nil {
+	if err := DeleteUser(context.Background(), key, svc, logger); err == nil {
 		t.Errorf("Deleted user when it should have failed")
 	} else if err.Error() != expectedError {
 		t.Errorf("Expected error to be '%s', but was '%s'", expectedError, err.Error())
@@ -253,7 +279,7 @@ func TestDeleteUser(t *testing.T) {
 }
 
 func TestGetUserDeleteInput(t *testing.T) {
-	key := &UserKey{UserId: "username"}
+	key := &UserKey{TenantId: "tenant1", UserId: "username"}
 	if input, err := getUserDeleteInput(key); err != nil {
 		t.Errorf("Failed to get input with error '%s'", err.Error())
 	} else {
@@ -265,10 +291,10 @@ func TestGetUserDeleteInput(t *testing.T) {
 
 		if input.Key == nil {
 			t.Error("User key should not have generated an empty map")
-		} else if input.Key["user_id"].S == nil {
-			t.Error("Expected user_id to be a string type")
-		} else if *input.Key["user_id"].S != key.UserId {
-			t.Errorf("Expected user_id to be '%s', but was '%s'", key.UserId, *input.Key["user_id"].S)
+		} else if input.Key[PartitionKey].S == nil {
+			t.Error("Expected pk to be a string type")
+		} else if *input.Key[PartitionKey].S != "tenant1#username" {
+			t.Errorf("Expected pk to be 'tenant1#username', but was '%s'", *input.Key[PartitionKey].S)
 		}
 	}
 }
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	ErrorDuplicateEntry     = "duplicate entry"
+	ErrorFieldRequired      = "field is required"
+	ErrorInvalidPhoneNumber = "invalid phone_number, expected E.164 such as +15555550123"
+	ErrorInvalidUrl         = "invalid url, expected an http or https address"
+	ErrorUnknownField       = "unknown field"
+	ErrorYearOutOfRange     = "year out of range"
+)
+
+// Violation codes say what kind of rule a FieldError broke, so clients can react without matching on messages
+const (
+	CodeDuplicate      = "duplicate"
+	CodeEndBeforeStart = "end_before_start"
+	CodeInvalidFormat  = "invalid_format"
+	CodeInvalidValue   = "invalid_value"
+	CodeOutOfRange     = "out_of_range"
+	CodeRequired       = "required"
+	CodeUnknownField   = "unknown_field"
+)
+
+const (
+	minYear = 1900
+	// maxYearsAhead allows for expected graduation dates
+	maxYearsAhead = 10
+)
+
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// ValidationError holds every violation found in a model, in the order the fields are checked. It reads as its first
+// violation, so callers that only report one error still get a useful message.
+type ValidationError struct {
+	Violations []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Violations[0].Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Violations[0]
+}
+
+// validator collects violations rather than stopping at the first one
+type validator struct {
+	violations []*FieldError
+}
+
+func (v *validator) add(field string, code string, err error) {
+	v.violations = append(v.violations, &FieldError{Field: field, Code: code, Err: err})
+}
+
+func (v *validator) check(ok bool, field string, code string, err error) {
+	if !ok {
+		v.add(field, code, err)
+	}
+}
+
+func (v *validator) required(value string, field string) {
+	v.check(len(value) > 0, field, CodeRequired, ErrFieldRequired)
+}
+
+// url checks an optional link is an absolute http or https address
+func (v *validator) url(value string, field string) {
+	if len(value) == 0 {
+		return
+	}
+
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0, field, CodeInvalidFormat, ErrInvalidUrl)
+}
+
+func (v *validator) year(year int, field string) {
+	if year == 0 {
+		return
+	}
+
+	v.check(year >= minYear && year <= time.Now().Year()+maxYearsAhead, field, CodeOutOfRange, ErrYearOutOfRange)
+}
+
+// dateRange checks end is not before start, reporting it against the end field
+func (v *validator) dateRange(start Date, end *Date, field string) {
+	v.check(end == nil || !end.Before(start), field, CodeEndBeforeStart, ErrEndBeforeStart)
+}
+
+// unique reports every entry whose key was already used earlier in the slice
+func (v *validator) unique(seen map[interface{}]bool, key interface{}, field string) {
+	v.check(!seen[key], field, CodeDuplicate, ErrDuplicateEntry)
+	seen[key] = true
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: v.violations}
+}
+
+func (v *validator) validateUser(user *User) {
+	v.check(isEmail(user.Email), "email", CodeInvalidFormat, ErrInvalidEmail)
+	v.check(len(user.UserId) > 0, "user_id", CodeRequired, ErrInvalidUserId)
+	v.url(user.Github, "github")
+	v.url(user.Linkedin, "linkedin")
+	if len(user.PhoneNumber) > 0 {
+		v.check(e164Regex.MatchString(user.PhoneNumber), "phone_number", CodeInvalidFormat, ErrInvalidPhoneNumber)
+	}
+
+	seen := make(map[interface{}]bool)
+	for i, cert := range user.Certifications {
+		path := fmt.Sprintf("certifications[%d]", i)
+		v.required(cert.Name, path+".name")
+		v.url(cert.BadgeLink, path+".badge_link")
+		v.dateRange(cert.DateAchieved, cert.DateExpires, path+".date_expires")
+		v.unique(seen, cert.Key(), path)
+	}
+
+	seen = make(map[interface{}]bool)
+	for i, degree := range user.Degrees {
+		path := fmt.Sprintf("degrees[%d]", i)
+		v.required(degree.Degree, path+".degree")
+		v.required(degree.School, path+".school")
+		v.year(degree.StartYear, path+".start_year")
+		v.year(degree.EndYear, path+".end_year")
+		v.check(degree.EndYear == 0 || degree.EndYear >= degree.StartYear, path+".end_year", CodeEndBeforeStart, ErrEndBeforeStart)
+		v.unique(seen, degree.Key(), path)
+	}
+
+	seen = make(map[interface{}]bool)
+	for i, exp := range user.Experience {
+		path := fmt.Sprintf("experience[%d]", i)
+		v.required(exp.Company, path+".company")
+		v.required(exp.JobTitle, path+".job_title")
+		v.year(exp.StartDate.Year, path+".start_date")
+		if exp.EndDate != nil {
+			v.year(exp.EndDate.Year, path+".end_date")
+		}
+		v.dateRange(exp.StartDate, exp.EndDate, path+".end_date")
+		v.unique(seen, exp.Key(), path)
+	}
+
+	seen = make(map[interface{}]bool)
+	for i, skill := range user.Skills {
+		path := fmt.Sprintf("skills[%d]", i)
+		v.required(skill.Name, path+".name")
+		v.check(skill.YearsOfExperience >= 0, path+".years_of_experience", CodeOutOfRange, ErrYearOutOfRange)
+		v.unique(seen, skill.Key(), path)
+	}
+
+	v.validateVisibility(user.Visibility)
+}
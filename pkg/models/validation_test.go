@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateUserCollectsViolations(t *testing.T) {
+	setup(t)
+	if err := ValidateUser(user); err != nil {
+		t.Fatalf("Expected the fixture to be valid, but got '%s'", err.Error())
+	}
+
+	user.Github = "github.com/user"
+	user.PhoneNumber = "555-0123"
+	user.Certifications[0].BadgeLink = "ftp://example.com/badge"
+	user.Degrees[0].StartYear = 1850
+	user.Experience = append(user.Experience, Experience{Company: user.Experience[0].Company, JobTitle: user.Experience[0].JobTitle}, Experience{})
+	user.Skills = append(user.Skills, Skill{YearsOfExperience: -1})
+
+	expected := []struct {
+		field string
+		code  string
+	}{
+		{"github", CodeInvalidFormat},
+		{"phone_number", CodeInvalidFormat},
+		{"certifications[0].badge_link", CodeInvalidFormat},
+		{"degrees[0].start_year", CodeOutOfRange},
+		{"experience[1]", CodeDuplicate},
+		{"experience[2].company", CodeRequired},
+		{"experience[2].job_title", CodeRequired},
+		{"skills[1].name", CodeRequired},
+		{"skills[1].years_of_experience", CodeOutOfRange},
+	}
+
+	err := ValidateUser(user)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, but got %v", err)
+	}
+
+	if len(expected) != len(validationErr.Violations) {
+		for _, violation := range validationErr.Violations {
+			t.Logf("%s %s: %s", violation.Field, violation.Code, violation.Error())
+		}
+		t.Fatalf("Expected %d violations, but got %d", len(expected), len(validationErr.Violations))
+	}
+
+	for i, violation := range validationErr.Violations {
+		if expected[i].field != violation.Field || expected[i].code != violation.Code {
+			t.Errorf("Expected violation %d to be %s %s, but was %s %s", i, expected[i].field, expected[i].code, violation.Field, violation.Code)
+		}
+	}
+
+	if ErrorInvalidUrl != err.Error() {
+		t.Errorf("Expected the error to read as its first violation, but was '%s'", err.Error())
+	}
+}
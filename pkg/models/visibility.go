@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	ErrorInvalidVisibility      = "invalid visibility"
+	ErrorUnknownVisibilityField = "unknown field or section for visibility"
+)
+
+type Visibility string
+
+const (
+	VisibilityPublic     Visibility = "public"
+	VisibilitySharedLink Visibility = "shared_link"
+	VisibilityPrivate    Visibility = "private"
+)
+
+// AccessLevel is how much of a resume the caller is entitled to see. Levels are ordered, so a caller can see anything
+// whose visibility requires a level at or below their own.
+type AccessLevel int
+
+const (
+	AccessPublic AccessLevel = iota
+	AccessSharedLink
+	AccessOwner
+)
+
+// VisibilitySettings are keyed by the JSON name of the field or section. Anything not listed falls back to
+// DefaultFieldVisibility, and then to public.
+type VisibilitySettings struct {
+	Fields   map[string]Visibility `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Sections map[string]Visibility `json:"sections,omitempty" yaml:"sections,omitempty"`
+}
+
+// DefaultFieldVisibility keeps contact details off the public resume unless the user opts in
+var DefaultFieldVisibility = map[string]Visibility{
+	"email":        VisibilitySharedLink,
+	"location":     VisibilitySharedLink,
+	"phone_number": VisibilitySharedLink,
+}
+
+var redactableFields = map[string]func(user *User){
+	"email":        func(user *User) { user.Email = "" },
+	"github":       func(user *User) { user.Github = "" },
+	"given_name":   func(user *User) { user.GivenName = "" },
+	"linkedin":     func(user *User) { user.Linkedin = "" },
+	"location":     func(user *User) { user.Location = "" },
+	"phone_number": func(user *User) { user.PhoneNumber = "" },
+	"summary":      func(user *User) { user.Summary = "" },
+	"sur_name":     func(user *User) { user.SurName = "" },
+}
+
+var redactableSections = map[string]func(user *User){
+	"certifications": func(user *User) { user.Certifications = nil },
+	"degrees":        func(user *User) { user.Degrees = nil },
+	"experience":     func(user *User) { user.Experience = nil },
+	"skills":         func(user *User) { user.Skills = nil },
+}
+
+func (v Visibility) requiredAccess() AccessLevel {
+	switch v {
+	case VisibilitySharedLink:
+		return AccessSharedLink
+	case VisibilityPrivate:
+		return AccessOwner
+	default:
+		return AccessPublic
+	}
+}
+
+func (v Visibility) isValid() bool {
+	return v == VisibilityPublic || v == VisibilitySharedLink || v == VisibilityPrivate
+}
+
+func (user *User) fieldVisibility(field string) Visibility {
+	if user.Visibility != nil {
+		if v, ok := user.Visibility.Fields[field]; ok {
+			return v
+		}
+	}
+
+	if v, ok := DefaultFieldVisibility[field]; ok {
+		return v
+	}
+
+	return VisibilityPublic
+}
+
+func (user *User) sectionVisibility(section string) Visibility {
+	if user.Visibility != nil {
+		if v, ok := user.Visibility.Sections[section]; ok {
+			return v
+		}
+	}
+
+	return VisibilityPublic
+}
+
+// Redact returns a copy of the user with everything the access level is not entitled to removed. The visibility
+// settings and owning organization are only returned to the owner.
+func Redact(user *User, level AccessLevel) *User {
+	redacted := *user
+	if level >= AccessOwner {
+		return &redacted
+	}
+
+	for field, redact := range redactableFields {
+		if user.fieldVisibility(field).requiredAccess() > level {
+			redact(&redacted)
+		}
+	}
+
+	for section, redact := range redactableSections {
+		if user.sectionVisibility(section).requiredAccess() > level {
+			redact(&redacted)
+		}
+	}
+
+	redacted.Visibility = nil
+	redacted.OrgId = ""
+	return &redacted
+}
+
+func (v *validator) validateVisibility(settings *VisibilitySettings) {
+	if settings == nil {
+		return
+	}
+
+	for _, field := range sortedVisibilityKeys(settings.Fields) {
+		path := fmt.Sprintf("visibility.fields.%s", field)
+		_, ok := redactableFields[field]
+		v.check(ok, path, CodeUnknownField, ErrUnknownVisibilityField)
+		v.check(settings.Fields[field].isValid(), path, CodeInvalidValue, ErrInvalidVisibility)
+	}
+
+	for _, section := range sortedVisibilityKeys(settings.Sections) {
+		path := fmt.Sprintf("visibility.sections.%s", section)
+		_, ok := redactableSections[section]
+		v.check(ok, path, CodeUnknownField, ErrUnknownVisibilityField)
+		v.check(settings.Sections[section].isValid(), path, CodeInvalidValue, ErrInvalidVisibility)
+	}
+}
+
+func sortedVisibilityKeys(m map[string]Visibility) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	setup(t)
+
+	public := Redact(user, AccessPublic)
+	if len(public.Email) != 0 || len(public.PhoneNumber) != 0 || len(public.Location) != 0 {
+		t.Errorf("Expected contact details to be hidden from the public by default, but got %+v", public)
+	}
+
+	if user.GivenName != public.GivenName || len(public.Experience) != 1 {
+		t.Errorf("Expected public fields to be kept, but got %+v", public)
+	}
+
+	if len(user.Email) == 0 {
+		t.Errorf("Expected redaction to leave the original user untouched")
+	}
+
+	shared := Redact(user, AccessSharedLink)
+	if user.Email != shared.Email || user.PhoneNumber != shared.PhoneNumber {
+		t.Errorf("Expected contact details to be visible through a shared link, but got %+v", shared)
+	}
+
+	user.Visibility = &VisibilitySettings{
+		Fields:   map[string]Visibility{"email": VisibilityPublic, "phone_number": VisibilityPrivate},
+		Sections: map[string]Visibility{"experience": VisibilitySharedLink, "skills": VisibilityPrivate},
+	}
+
+	public = Redact(user, AccessPublic)
+	if user.Email != public.Email {
+		t.Errorf("Expected email to be public once opted in, but was '%s'", public.Email)
+	}
+
+	if len(public.Experience) != 0 || len(public.Skills) != 0 {
+		t.Errorf("Expected hidden sections to be removed, but got %+v", public)
+	}
+
+	if public.Visibility != nil {
+		t.Errorf("Expected visibility settings to be removed for non-owners")
+	}
+
+	shared = Redact(user, AccessSharedLink)
+	if len(shared.PhoneNumber) != 0 || len(shared.Skills) != 0 || len(shared.Experience) != 1 {
+		t.Errorf("Expected private fields hidden and shared sections visible, but got %+v", shared)
+	}
+
+	owner := Redact(user, AccessOwner)
+	if user.PhoneNumber != owner.PhoneNumber || len(owner.Skills) != 1 || owner.Visibility == nil {
+		t.Errorf("Expected the owner to see everything, but got %+v", owner)
+	}
+}
+
+func TestValidateVisibility(t *testing.T) {
+	setup(t)
+
+	user.Visibility = &VisibilitySettings{Fields: map[string]Visibility{"email": "everyone"}}
+	if err := ValidateUser(user); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorInvalidVisibility != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidVisibility, err.Error())
+	} else if fieldErr := (*FieldError)(nil); !errors.As(err, &fieldErr) || "visibility.fields.email" != fieldErr.Field {
+		t.Errorf("Expected error to point at visibility.fields.email")
+	}
+
+	user.Visibility = &VisibilitySettings{Sections: map[string]Visibility{"hobbies": VisibilityPrivate}}
+	if err := ValidateUser(user); err == nil {
+		t.Errorf("Expected to get an error and no err was returned")
+	} else if ErrorUnknownVisibilityField != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorUnknownVisibilityField, err.Error())
+	}
+
+	user.Visibility = &VisibilitySettings{Sections: map[string]Visibility{"skills": VisibilityPrivate}}
+	if err := ValidateUser(user); err != nil {
+		t.Errorf("Expected valid visibility settings to pass, but got '%s'", err.Error())
+	}
+}
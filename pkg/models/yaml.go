@@ -0,0 +1,172 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserDocument is a user decoded from YAML along with the parsed document, so that errors can point back at the line
+// the offending field was written on
+type UserDocument struct {
+	User *User
+	root *yaml.Node
+}
+
+func MarshalUserYAML(user *User) ([]byte, error) {
+	return yaml.Marshal(user)
+}
+
+// UnmarshalUserYAML rejects fields the user doesn't have, so a misspelled key isn't silently dropped
+func UnmarshalUserYAML(data []byte) (*UserDocument, error) {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+
+	user := &User{}
+	if root.Kind == 0 {
+		return &UserDocument{User: user, root: root}, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(user); err != nil {
+		return nil, yamlDecodeError(err)
+	}
+
+	return &UserDocument{User: user, root: root}, nil
+}
+
+// unknownYAMLFieldRegex matches how yaml.v3 reports a field that isn't in the struct
+var unknownYAMLFieldRegex = regexp.MustCompile(`^line (\d+): field (\S+) not found in type`)
+
+// checkKnownYAMLFields fails on a key of the mapping that isn't one of v's fields, the same way a decoder with
+// KnownFields would. Decoding a node directly, as an UnmarshalYAML has to, doesn't check them.
+func checkKnownYAMLFields(node *yaml.Node, v interface{}) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	known := make(map[string]bool)
+	addYAMLFields(t, known)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; !known[key.Value] {
+			return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: field %s not found in type %s", key.Line, key.Value, t)}}
+		}
+	}
+
+	return nil
+}
+
+func addYAMLFields(t reflect.Type, known map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		switch {
+		case tag[0] == "-":
+		case len(tag) > 1 && tag[1] == "inline":
+			addYAMLFields(field.Type, known)
+		case len(tag[0]) > 0:
+			known[tag[0]] = true
+		case len(field.PkgPath) == 0:
+			known[strings.ToLower(field.Name)] = true
+		}
+	}
+}
+
+func yamlDecodeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	for _, msg := range typeErr.Errors {
+		if match := unknownYAMLFieldRegex.FindStringSubmatch(msg); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return &FieldError{Field: match[2], Line: line, Code: CodeUnknownField, Err: ErrUnknownField}
+		}
+	}
+
+	return err
+}
+
+// Line returns the line a field was defined on, or 0 when the field is not in the document. Fields are addressed the
+// same way as FieldError.Field, e.g. "email" or "experience[1].company". A missing field falls back to the line of
+// its closest parent.
+func (d *UserDocument) Line(field string) int {
+	node := d.root
+	if node == nil {
+		return 0
+	}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := node.Line
+	for _, segment := range splitFieldPath(field) {
+		node = childNode(node, segment)
+		if node == nil {
+			break
+		}
+		line = node.Line
+	}
+
+	return line
+}
+
+func splitFieldPath(field string) []string {
+	var segments []string
+	for _, part := range strings.Split(field, ".") {
+		for len(part) > 0 {
+			open := strings.Index(part, "[")
+			if open == -1 {
+				segments = append(segments, part)
+				break
+			}
+
+			if open > 0 {
+				segments = append(segments, part[:open])
+			}
+
+			end := strings.Index(part, "]")
+			if end < open {
+				break
+			}
+			segments = append(segments, part[open:end+1])
+			part = part[end+1:]
+		}
+	}
+
+	return segments
+}
+
+func childNode(node *yaml.Node, segment string) *yaml.Node {
+	if strings.HasPrefix(segment, "[") {
+		idx, err := strconv.Atoi(strings.Trim(segment, "[]"))
+		if err != nil || node.Kind != yaml.SequenceNode || idx < 0 || idx >= len(node.Content) {
+			return nil
+		}
+		return node.Content[idx]
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == segment {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
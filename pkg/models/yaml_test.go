@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestUserYAMLRoundTrip(t *testing.T) {
+	setup(t)
+	// The tenant is never serialized, it always comes from the caller
+	user.TenantId = ""
+
+	data, err := MarshalUserYAML(user)
+	if err != nil {
+		t.Fatalf("Failed to marshal user to YAML: %s", err.Error())
+	}
+
+	doc, err := UnmarshalUserYAML(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal user from YAML: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(user, doc.User) {
+		t.Errorf("Expected user to survive a YAML round trip, but got %+v", doc.User)
+	}
+
+	jsonData, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("Failed to marshal user to JSON: %s", err.Error())
+	}
+
+	var fromJSON, fromYAML map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fromJSON); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %s", err.Error())
+	}
+	if err := yaml.Unmarshal(data, &fromYAML); err != nil {
+		t.Fatalf("Failed to unmarshal YAML: %s", err.Error())
+	}
+
+	normalizedYAML, _ := json.Marshal(fromYAML)
+	normalizedJSON, _ := json.Marshal(fromJSON)
+	if string(normalizedJSON) != string(normalizedYAML) {
+		t.Errorf("Expected YAML fields to match JSON fields\nJSON: %s\nYAML: %s", normalizedJSON, normalizedYAML)
+	}
+}
+
+func TestUnmarshalUserYAML(t *testing.T) {
+	data := []byte(`user_id: user1
+email: not an email
+experience:
+  - company: Co
+    job_title: SRE
+  - company: Other Co
+    job_title: Dev
+    start_year: 2020
+`)
+
+	doc, err := UnmarshalUserYAML(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal user from YAML: %s", err.Error())
+	}
+
+	if "Other Co" != doc.User.Experience[1].Company {
+		t.Errorf("Expected second company to be 'Other Co', but was '%s'", doc.User.Experience[1].Company)
+	}
+
+	lines := map[string]int{
+		"user_id":                  1,
+		"email":                    2,
+		"experience[1].company":    6,
+		"experience[1].start_year": 8,
+		"experience[0].start_year": 4,
+		"summary":                  1,
+	}
+	for field, expected := range lines {
+		if line := doc.Line(field); expected != line {
+			t.Errorf("Expected field '%s' to be on line %d, but was %d", field, expected, line)
+		}
+	}
+
+	if _, err := UnmarshalUserYAML([]byte("user_id: [unterminated")); err == nil {
+		t.Errorf("Expected invalid YAML to fail to unmarshal")
+	}
+
+	if _, err := UnmarshalUserYAML([]byte("skills: not a list")); err == nil {
+		t.Errorf("Expected mistyped YAML to fail to unmarshal")
+	}
+	var fieldErr *FieldError
+	if _, err := UnmarshalUserYAML([]byte("user_id: user1\nskils: []\n")); !errors.As(err, &fieldErr) {
+		t.Errorf("Expected an unknown field to be a field error, but was %v", err)
+	} else if "skils" != fieldErr.Field || 2 != fieldErr.Line || !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected an unknown field error for 'skils' on line 2, but was %+v", fieldErr)
+	}
+
+	nested := "experience:\n  - company: x\n    job_title: y\n    start_month: January\n    start_year: 2020\n    responsibilites: [typo]\n"
+	if _, err := UnmarshalUserYAML([]byte(nested)); !errors.As(err, &fieldErr) {
+		t.Errorf("Expected an unknown experience field to be a field error, but was %v", err)
+	} else if "responsibilites" != fieldErr.Field || 6 != fieldErr.Line || !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected an unknown field error for 'responsibilites' on line 6, but was %+v", fieldErr)
+	}
+}
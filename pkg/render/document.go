@@ -0,0 +1,147 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+// Document is a format agnostic layout of a resume that the Markdown, HTML and PDF renderers share
+type Document struct {
+	Title    string
+	Subtitle []string
+	Sections []Section
+}
+
+type Section struct {
+	Heading   string
+	Paragraph string
+	Entries   []Entry
+}
+
+type Entry struct {
+	Title   string
+	Detail  string
+	Bullets []string
+}
+
+func FromUser(user *models.User) Document {
+	doc := Document{Title: strings.TrimSpace(fmt.Sprintf("%s %s", user.GivenName, user.SurName))}
+	if len(doc.Title) == 0 {
+		doc.Title = user.UserId
+	}
+
+	for _, contact := range []string{user.Email, user.PhoneNumber, user.Location, user.Github, user.Linkedin} {
+		if len(contact) > 0 {
+			doc.Subtitle = append(doc.Subtitle, contact)
+		}
+	}
+
+	if len(user.Summary) > 0 {
+		doc.Sections = append(doc.Sections, Section{Heading: "Summary", Paragraph: user.Summary})
+	}
+
+	if len(user.Experience) > 0 {
+		section := Section{Heading: "Experience"}
+		for _, exp := range user.Experience {
+			section.Entries = append(section.Entries, Entry{
+				Title:   fmt.Sprintf("%s, %s", exp.JobTitle, exp.Company),
+				Detail:  dateRange(formatDate(exp.StartDate), formatDate(endDate(exp.EndDate))),
+				Bullets: exp.Responsibilities,
+			})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if len(user.Skills) > 0 {
+		section := Section{Heading: "Skills"}
+		for _, skill := range user.Skills {
+			entry := Entry{Title: skill.Name}
+			if skill.YearsOfExperience == 1 {
+				entry.Detail = "1 year"
+			} else if skill.YearsOfExperience > 1 {
+				entry.Detail = fmt.Sprintf("%d years", skill.YearsOfExperience)
+			}
+			section.Entries = append(section.Entries, entry)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if len(user.Degrees) > 0 {
+		section := Section{Heading: "Education"}
+		for _, degree := range user.Degrees {
+			section.Entries = append(section.Entries, Entry{
+				Title:  fmt.Sprintf("%s %s, %s", degree.Degree, degree.Major, degree.School),
+				Detail: dateRange(formatYear(degree.StartYear), formatYear(degree.EndYear)),
+			})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if len(user.Certifications) > 0 {
+		section := Section{Heading: "Certifications"}
+		for _, cert := range user.Certifications {
+			entry := Entry{Title: cert.Name, Detail: formatDate(cert.DateAchieved)}
+			if cert.DateExpires != nil {
+				entry.Detail = fmt.Sprintf("%s (expires %s)", formatDate(cert.DateAchieved), formatDate(*cert.DateExpires))
+			}
+			if len(cert.BadgeLink) > 0 {
+				entry.Bullets = []string{cert.BadgeLink}
+			}
+			section.Entries = append(section.Entries, entry)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	return doc
+}
+
+func FromMessage(title string, message string) Document {
+	return Document{
+		Title:    title,
+		Sections: []Section{{Paragraph: message}},
+	}
+}
+
+// formatDate writes the date as precisely as it is known, e.g. "2019", "October 2019" or "October 28, 2019"
+func formatDate(date models.Date) string {
+	switch {
+	case date.IsZero():
+		return ""
+	case date.Month == 0:
+		return formatYear(date.Year)
+	case date.Day == 0:
+		return fmt.Sprintf("%s %d", date.Month, date.Year)
+	default:
+		return fmt.Sprintf("%s %d, %d", date.Month, date.Day, date.Year)
+	}
+}
+
+func endDate(date *models.Date) models.Date {
+	if date == nil {
+		return models.Date{}
+	}
+
+	return *date
+}
+
+func formatYear(year int) string {
+	if year == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", year)
+}
+
+func dateRange(start string, end string) string {
+	if len(start) == 0 {
+		return end
+	}
+
+	if len(end) == 0 {
+		return fmt.Sprintf("%s - Present", start)
+	}
+
+	return fmt.Sprintf("%s - %s", start, end)
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("resume").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{- if .Subtitle}}
+<p>{{join .Subtitle " | "}}</p>
+{{- end}}
+</header>
+{{- range .Sections}}
+<section>
+{{- if .Heading}}
+<h2>{{.Heading}}</h2>
+{{- end}}
+{{- if .Paragraph}}
+<p>{{.Paragraph}}</p>
+{{- end}}
+{{- range .Entries}}
+<article>
+<h3>{{.Title}}</h3>
+{{- if .Detail}}
+<p><em>{{.Detail}}</em></p>
+{{- end}}
+{{- if .Bullets}}
+<ul>
+{{- range .Bullets}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</article>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+func HTML(doc Document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+func Markdown(doc Document) []byte {
+	var sb strings.Builder
+	if len(doc.Title) > 0 {
+		sb.WriteString(fmt.Sprintf("# %s\n\n", doc.Title))
+	}
+
+	if len(doc.Subtitle) > 0 {
+		sb.WriteString(fmt.Sprintf("%s\n\n", strings.Join(doc.Subtitle, " | ")))
+	}
+
+	for _, section := range doc.Sections {
+		if len(section.Heading) > 0 {
+			sb.WriteString(fmt.Sprintf("## %s\n\n", section.Heading))
+		}
+
+		if len(section.Paragraph) > 0 {
+			sb.WriteString(fmt.Sprintf("%s\n\n", section.Paragraph))
+		}
+
+		for _, entry := range section.Entries {
+			sb.WriteString(fmt.Sprintf("### %s\n\n", entry.Title))
+			if len(entry.Detail) > 0 {
+				sb.WriteString(fmt.Sprintf("*%s*\n\n", entry.Detail))
+			}
+
+			for _, bullet := range entry.Bullets {
+				sb.WriteString(fmt.Sprintf("- %s\n", bullet))
+			}
+
+			if len(entry.Bullets) > 0 {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	return []byte(strings.TrimRight(sb.String(), "\n") + "\n")
+}
@@ -0,0 +1,186 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfPageWidth    = 612
+	pdfPageHeight   = 792
+	pdfMargin       = 54
+	pdfCharsPerLine = 90
+)
+
+type pdfLine struct {
+	text string
+	font string
+	size int
+}
+
+// PDF lays the document out as plain text on US Letter pages using the standard Helvetica fonts, so no font files
+// need to be embedded
+func PDF(doc Document) []byte {
+	return writePDF(paginate(layout(doc)))
+}
+
+// PageCount is how many pages the document takes up as a PDF
+func PageCount(doc Document) int {
+	return len(paginate(layout(doc)))
+}
+
+func layout(doc Document) []pdfLine {
+	var lines []pdfLine
+	addWrapped := func(text string, font string, size int, indent string) {
+		for i, wrapped := range wrap(text, pdfCharsPerLine-len(indent)) {
+			if i == 0 {
+				lines = append(lines, pdfLine{text: indent + wrapped, font: font, size: size})
+			} else {
+				lines = append(lines, pdfLine{text: strings.Repeat(" ", len(indent)) + wrapped, font: font, size: size})
+			}
+		}
+	}
+
+	if len(doc.Title) > 0 {
+		addWrapped(doc.Title, "F2", 18, "")
+	}
+
+	if len(doc.Subtitle) > 0 {
+		addWrapped(strings.Join(doc.Subtitle, " | "), "F1", 10, "")
+	}
+
+	for _, section := range doc.Sections {
+		lines = append(lines, pdfLine{size: 10})
+		if len(section.Heading) > 0 {
+			addWrapped(section.Heading, "F2", 14, "")
+		}
+
+		if len(section.Paragraph) > 0 {
+			addWrapped(section.Paragraph, "F1", 10, "")
+		}
+
+		for _, entry := range section.Entries {
+			addWrapped(entry.Title, "F2", 11, "")
+			if len(entry.Detail) > 0 {
+				addWrapped(entry.Detail, "F1", 10, "")
+			}
+
+			for _, bullet := range entry.Bullets {
+				addWrapped(bullet, "F1", 10, "- ")
+			}
+		}
+	}
+
+	return lines
+}
+
+func paginate(lines []pdfLine) [][]pdfLine {
+	var pages [][]pdfLine
+	var page []pdfLine
+	y := pdfPageHeight - pdfMargin
+	for _, line := range lines {
+		height := line.size + line.size/2
+		if y-height < pdfMargin && len(page) > 0 {
+			pages = append(pages, page)
+			page = nil
+			y = pdfPageHeight - pdfMargin
+		}
+		page = append(page, line)
+		y -= height
+	}
+
+	if len(page) > 0 || len(pages) == 0 {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+func writePDF(pages [][]pdfLine) []byte {
+	// Objects 1-4 are fixed: catalog, page tree and the two fonts. Each page then takes two objects, the page and
+	// its content stream.
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	var kids []string
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2,
+		))
+
+		var content bytes.Buffer
+		y := pdfPageHeight - pdfMargin
+		for _, line := range page {
+			y -= line.size + line.size/2
+			if len(line.text) == 0 {
+				continue
+			}
+			content.WriteString(fmt.Sprintf("BT /%s %d Tf %d %d Td (%s) Tj ET\n", line.font, line.size, pdfMargin, y, escapePDF(line.text)))
+		}
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		buf.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, object))
+	}
+
+	xref := buf.Len()
+	buf.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1))
+	for _, offset := range offsets {
+		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	buf.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref))
+
+	return buf.Bytes()
+}
+
+func escapePDF(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r < 32 || r > 126:
+			sb.WriteRune('?')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		if len(current) == 0 {
+			current = word
+		} else if len(current)+1+len(word) <= width {
+			current += " " + word
+		} else {
+			lines = append(lines, current)
+			current = word
+		}
+	}
+
+	if len(current) > 0 || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+)
+
+func testUser() *models.User {
+	return &models.User{
+		UserId:    "user1",
+		Email:     "user@domain.com",
+		GivenName: "John",
+		SurName:   "Doe",
+		Summary:   "My <awesome> summary",
+		Experience: []models.Experience{
+			{
+				Company:          "Co",
+				JobTitle:         "SRE",
+				StartDate:        models.Date{Year: 2020, Month: time.May},
+				Responsibilities: []string{"Kept (most) things running"},
+			},
+		},
+		Skills: []models.Skill{{Name: "Go", YearsOfExperience: 2}},
+	}
+}
+
+func TestFromUser(t *testing.T) {
+	doc := FromUser(testUser())
+	if "John Doe" != doc.Title {
+		t.Errorf("Expected title to be 'John Doe', but was '%s'", doc.Title)
+	}
+
+	if len(doc.Sections) != 3 {
+		t.Fatalf("Expected 3 sections, but there were %d", len(doc.Sections))
+	}
+
+	experience := doc.Sections[1].Entries[0]
+	if "SRE, Co" != experience.Title {
+		t.Errorf("Expected experience title to be 'SRE, Co', but was '%s'", experience.Title)
+	}
+
+	if "May 2020 - Present" != experience.Detail {
+		t.Errorf("Expected experience detail to be 'May 2020 - Present', but was '%s'", experience.Detail)
+	}
+
+	if doc := FromUser(&models.User{UserId: "user1"}); "user1" != doc.Title {
+		t.Errorf("Expected title to fall back to the user_id, but was '%s'", doc.Title)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := string(Markdown(FromUser(testUser())))
+	for _, expected := range []string{"# John Doe\n", "## Experience\n", "### SRE, Co\n", "- Kept (most) things running\n"} {
+		if !strings.Contains(md, expected) {
+			t.Errorf("Expected markdown to contain '%s', but was '%s'", expected, md)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	html, err := HTML(FromUser(testUser()))
+	if err != nil {
+		t.Fatalf("Failed to render HTML: %s", err.Error())
+	}
+
+	if !bytes.Contains(html, []byte("My &lt;awesome&gt; summary")) {
+		t.Errorf("Expected HTML to escape the summary, but was '%s'", string(html))
+	}
+}
+
+func TestPDF(t *testing.T) {
+	pdf := PDF(FromUser(testUser()))
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) {
+		t.Errorf("Expected PDF header")
+	}
+
+	if !bytes.Contains(pdf, []byte(`(- Kept \(most\) things running) Tj`)) {
+		t.Errorf("Expected PDF to contain escaped responsibility text")
+	}
+
+	if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Errorf("Expected PDF trailer")
+	}
+
+	var lines []pdfLine
+	for i := 0; i < 100; i++ {
+		lines = append(lines, pdfLine{text: "line", font: "F1", size: 10})
+	}
+	if pages := paginate(lines); len(pages) < 2 {
+		t.Errorf("Expected long documents to span multiple pages, but got %d", len(pages))
+	}
+}
@@ -0,0 +1,95 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	ErrorInvalidToken  = "invalid share token"
+	ErrorMissingSecret = "share token secret is not configured"
+	ErrorTokenExpired  = "share token has expired"
+)
+
+var (
+	ErrInvalidToken  = errors.New(ErrorInvalidToken)
+	ErrMissingSecret = errors.New(ErrorMissingSecret)
+	ErrTokenExpired  = errors.New(ErrorTokenExpired)
+)
+
+// Claims are carried in the token so an expired or forged link is rejected before the share is looked up
+type Claims struct {
+	ShareId   string `json:"sid"`
+	UserId    string `json:"uid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func NewShareId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Sign produces "<payload>.<signature>" where both halves are unpadded base64url, so the token is safe to use as a
+// path segment
+func Sign(claims Claims, secret []byte) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrMissingSecret
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(encoded, secret)), nil
+}
+
+func Verify(token string, secret []byte, now time.Time) (*Claims, error) {
+	if len(secret) == 0 {
+		return nil, ErrMissingSecret
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(parts[0], secret)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil || len(claims.ShareId) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+func sign(payload string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
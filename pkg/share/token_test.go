@@ -0,0 +1,64 @@
+package share
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1600000000, 0)
+	claims := Claims{ShareId: "share1", UserId: "user1", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	token, err := Sign(claims, secret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %s", err.Error())
+	}
+
+	if verified, err := Verify(token, secret, now); err != nil {
+		t.Errorf("Failed to verify token: %s", err.Error())
+	} else if claims != *verified {
+		t.Errorf("Expected claims to be %+v, but were %+v", claims, *verified)
+	}
+
+	if _, err := Verify(token, secret, now.Add(2*time.Hour)); err == nil {
+		t.Errorf("Expected expired token to fail verification")
+	} else if ErrorTokenExpired != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorTokenExpired, err.Error())
+	}
+
+	if _, err := Verify(token, []byte("other secret"), now); err == nil {
+		t.Errorf("Expected token signed with another secret to fail verification")
+	} else if ErrorInvalidToken != err.Error() {
+		t.Errorf("Expected error to be '%s', but was '%s'", ErrorInvalidToken, err.Error())
+	}
+
+	parts := strings.Split(token, ".")
+	forged, _ := Sign(Claims{ShareId: "share1", UserId: "user2", ExpiresAt: claims.ExpiresAt}, []byte("other secret"))
+	if _, err := Verify(strings.Split(forged, ".")[0]+"."+parts[1], secret, now); err == nil {
+		t.Errorf("Expected token with a swapped payload to fail verification")
+	}
+
+	for _, bad := range []string{"", "abc", "a.b.c", "!!.!!"} {
+		if _, err := Verify(bad, secret, now); err == nil {
+			t.Errorf("Expected '%s' to fail verification", bad)
+		}
+	}
+
+	if _, err := Sign(claims, nil); err == nil {
+		t.Errorf("Expected signing without a secret to fail")
+	}
+}
+
+func TestNewShareId(t *testing.T) {
+	first, err := NewShareId()
+	if err != nil {
+		t.Fatalf("Failed to generate share ID: %s", err.Error())
+	}
+
+	second, _ := NewShareId()
+	if len(first) != 32 || first == second {
+		t.Errorf("Expected unique 32 character IDs, but got '%s' and '%s'", first, second)
+	}
+}
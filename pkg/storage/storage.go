@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorCircuitOpen = "storage is unavailable, try again later"
+
+	DefaultMaxAttempts      = 3
+	DefaultBaseDelay        = 50 * time.Millisecond
+	DefaultMaxDelay         = time.Second
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New(ErrorCircuitOpen)
+
+// CircuitOpenError is returned without calling DynamoDB while the circuit breaker is open. It matches ErrCircuitOpen.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return ErrorCircuitOpen
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// throttleCodes are rejected before DynamoDB does anything, so any operation can be retried after them
+var throttleCodes = map[string]bool{
+	dynamodb.ErrCodeProvisionedThroughputExceededException: true,
+	dynamodb.ErrCodeRequestLimitExceeded:                   true,
+	"ThrottlingException":                                  true,
+}
+
+// transientCodes may have been applied before failing, so only idempotent operations are retried after them
+var transientCodes = map[string]bool{
+	dynamodb.ErrCodeInternalServerError: true,
+	"ServiceUnavailable":                true,
+	request.ErrCodeRequestError:         true,
+}
+
+type Config struct {
+	MaxAttempts      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Resilient wraps a DynamoDB client so the item operations the models use are retried with jittered exponential
+// backoff, and fail fast while DynamoDB keeps failing. Every other operation goes straight to the wrapped client.
+type Resilient struct {
+	dynamodbiface.DynamoDBAPI
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	breaker     *breaker
+	logger      *zap.Logger
+	sleep       func(ctx context.Context, d time.Duration) error
+	jitter      func() float64
+}
+
+// New wraps svc, using the defaults for anything left unset in cfg
+func New(svc dynamodbiface.DynamoDBAPI, cfg Config, logger *zap.Logger) *Resilient {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultBaseDelay
+	}
+
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultMaxDelay
+	}
+
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
+
+	return &Resilient{
+		DynamoDBAPI: svc,
+		maxAttempts: cfg.MaxAttempts,
+		baseDelay:   cfg.BaseDelay,
+		maxDelay:    cfg.MaxDelay,
+		breaker:     newBreaker(cfg.FailureThreshold, cfg.Cooldown),
+		logger:      logger,
+		sleep:       sleep,
+		jitter:      rand.Float64,
+	}
+}
+
+func (r *Resilient) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	var out *dynamodb.GetItemOutput
+	err := r.do(ctx, "GetItem", true, func() (err error) {
+		out, err = r.DynamoDBAPI.GetItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (r *Resilient) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	var out *dynamodb.QueryOutput
+	err := r.do(ctx, "Query", true, func() (err error) {
+		out, err = r.DynamoDBAPI.QueryWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+// PutItemWithContext is treated as idempotent since writing the same item twice leaves it the same. A conditional put
+// isn't, since one that was applied before the failure would report its condition failing on retry, so like an update
+// it is only retried when throttled.
+func (r *Resilient) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	var out *dynamodb.PutItemOutput
+	err := r.do(ctx, "PutItem", input.ConditionExpression == nil, func() (err error) {
+		out, err = r.DynamoDBAPI.PutItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (r *Resilient) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	var out *dynamodb.DeleteItemOutput
+	err := r.do(ctx, "DeleteItem", true, func() (err error) {
+		out, err = r.DynamoDBAPI.DeleteItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+// UpdateItemWithContext is not idempotent, since updates like counting share views add to what is stored
+func (r *Resilient) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	var out *dynamodb.UpdateItemOutput
+	err := r.do(ctx, "UpdateItem", false, func() (err error) {
+		out, err = r.DynamoDBAPI.UpdateItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+// TransactWriteItemsWithContext is not idempotent, since transactions are conditioned on what they change
+func (r *Resilient) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	var out *dynamodb.TransactWriteItemsOutput
+	err := r.do(ctx, "TransactWriteItems", false, func() (err error) {
+		out, err = r.DynamoDBAPI.TransactWriteItemsWithContext(ctx, input, opts...)
+		return err
+	})
+	return out, err
+}
+
+// do runs op until it succeeds, fails in a way that retrying won't fix, runs out of attempts, or the next attempt would
+// start after the context's deadline
+func (r *Resilient) do(ctx context.Context, operation string, idempotent bool, op func() error) error {
+	if wait, ok := r.breaker.allow(); !ok {
+		return &CircuitOpenError{RetryAfter: wait}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil {
+			r.breaker.success()
+			return nil
+		}
+
+		throttled, transient := classify(err)
+		if !throttled && !transient {
+			// DynamoDB answered, it just didn't like the request
+			if isCanceled(err) {
+				r.breaker.release()
+			} else {
+				r.breaker.success()
+			}
+			return err
+		}
+
+		if attempt >= r.maxAttempts || (!throttled && !idempotent) {
+			r.breaker.failure()
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			r.breaker.failure()
+			return err
+		}
+
+		r.logger.Warn("Retrying storage call", zap.String("operation", operation), zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+		if sleepErr := r.sleep(ctx, delay); sleepErr != nil {
+			r.breaker.release()
+			return err
+		}
+	}
+}
+
+// backoff is a random delay up to the exponentially growing cap for the attempt
+func (r *Resilient) backoff(attempt int) time.Duration {
+	ceiling := float64(r.baseDelay) * math.Pow(2, float64(attempt-1))
+	if ceiling > float64(r.maxDelay) {
+		ceiling = float64(r.maxDelay)
+	}
+
+	return time.Duration(r.jitter() * ceiling)
+}
+
+func classify(err error) (throttled bool, transient bool) {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false, false
+	}
+
+	return throttleCodes[aerr.Code()], transientCodes[aerr.Code()]
+}
+
+// isCanceled reports whether the call was abandoned by the caller, which says nothing about DynamoDB's health
+func isCanceled(err error) bool {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == request.CanceledErrorCode {
+		return true
+	}
+
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
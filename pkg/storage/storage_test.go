@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	mocks "github.com/bkimbrough88/resume-backend/pkg"
+	"go.uber.org/zap"
+)
+
+var now = time.Unix(1600000000, 0)
+
+func setup(t *testing.T, cfg Config) *Resilient {
+	t.Helper()
+
+	r := New(mocks.DynamoServiceMock{}, cfg, zap.NewNop())
+	r.sleep = func(context.Context, time.Duration) error { return nil }
+	r.breaker.now = func() time.Time { return now }
+	return r
+}
+
+// failGetItem makes the mock fail with code the first failures times it is called, counting every call
+func failGetItem(code string, failures int) *int {
+	calls := 0
+	mocks.GetItemMock = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		calls++
+		if calls <= failures {
+			return nil, awserr.New(code, "injected failure", nil)
+		}
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &calls
+}
+
+func TestRetriesThrottling(t *testing.T) {
+	r := setup(t, Config{MaxAttempts: 3})
+
+	calls := failGetItem(dynamodb.ErrCodeProvisionedThroughputExceededException, 2)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err != nil {
+		t.Errorf("Expected the call to succeed after retrying, but got '%s'", err.Error())
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, but there were %d", *calls)
+	}
+
+	calls = failGetItem(dynamodb.ErrCodeProvisionedThroughputExceededException, 5)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil {
+		t.Errorf("Expected the call to fail once out of attempts")
+	}
+	if *calls != 3 {
+		t.Errorf("Expected to stop after 3 calls, but there were %d", *calls)
+	}
+}
+
+func TestDoesNotRetryPermanentErrors(t *testing.T) {
+	r := setup(t, Config{})
+
+	calls := failGetItem(dynamodb.ErrCodeConditionalCheckFailedException, 1)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil {
+		t.Errorf("Expected the error to be returned")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, but there were %d", *calls)
+	}
+}
+
+func TestRetriesUpdateOnlyWhenThrottled(t *testing.T) {
+	r := setup(t, Config{})
+
+	calls := 0
+	code := dynamodb.ErrCodeInternalServerError
+	mocks.UpdateItemMock = func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		calls++
+		if calls == 1 {
+			return nil, awserr.New(code, "injected failure", nil)
+		}
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+
+	if _, err := r.UpdateItemWithContext(context.Background(), &dynamodb.UpdateItemInput{}); err == nil {
+		t.Errorf("Expected an update that may have been applied not to be retried")
+	}
+
+	calls = 0
+	code = dynamodb.ErrCodeRequestLimitExceeded
+	if _, err := r.UpdateItemWithContext(context.Background(), &dynamodb.UpdateItemInput{}); err != nil {
+		t.Errorf("Expected a throttled update to be retried, but got '%s'", err.Error())
+	}
+}
+
+func TestRetriesConditionalPutOnlyWhenThrottled(t *testing.T) {
+	r := setup(t, Config{})
+
+	calls := 0
+	code := dynamodb.ErrCodeInternalServerError
+	mocks.PutItemMock = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		calls++
+		if calls == 1 {
+			return nil, awserr.New(code, "injected failure", nil)
+		}
+		return &dynamodb.PutItemOutput{}, nil
+	}
+
+	conditional := &dynamodb.PutItemInput{ConditionExpression: aws.String("attribute_not_exists(pk)")}
+	if _, err := r.PutItemWithContext(context.Background(), conditional); err == nil {
+		t.Errorf("Expected a conditional put that may have been applied not to be retried")
+	}
+
+	calls = 0
+	if _, err := r.PutItemWithContext(context.Background(), &dynamodb.PutItemInput{}); err != nil {
+		t.Errorf("Expected an unconditional put to be retried, but got '%s'", err.Error())
+	}
+
+	calls = 0
+	code = dynamodb.ErrCodeRequestLimitExceeded
+	if _, err := r.PutItemWithContext(context.Background(), conditional); err != nil {
+		t.Errorf("Expected a throttled conditional put to be retried, but got '%s'", err.Error())
+	}
+}
+
+func TestHonorsDeadline(t *testing.T) {
+	r := setup(t, Config{BaseDelay: time.Hour, MaxDelay: time.Hour})
+	r.jitter = func() float64 { return 1 }
+
+	calls := failGetItem(dynamodb.ErrCodeProvisionedThroughputExceededException, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := r.GetItemWithContext(ctx, &dynamodb.GetItemInput{}); err == nil {
+		t.Errorf("Expected the call to fail rather than wait past the deadline")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, but there were %d", *calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	r := setup(t, Config{MaxAttempts: 1, FailureThreshold: 2, Cooldown: time.Minute})
+
+	calls := failGetItem(dynamodb.ErrCodeInternalServerError, 3)
+	for i := 0; i < 2; i++ {
+		if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil {
+			t.Errorf("Expected call %d to fail", i+1)
+		}
+	}
+
+	_, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+	var circuitErr *CircuitOpenError
+	if !errors.As(err, &circuitErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the circuit to be open, but got '%v'", err)
+	} else if circuitErr.RetryAfter != time.Minute {
+		t.Errorf("Expected to retry after a minute, but was %s", circuitErr.RetryAfter)
+	}
+	if *calls != 2 {
+		t.Errorf("Expected DynamoDB not to be called while the circuit is open, but it was called %d times", *calls)
+	}
+
+	// The probe after the cooldown fails, so the circuit opens again
+	now = now.Add(time.Minute)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); errors.Is(err, ErrCircuitOpen) || err == nil {
+		t.Errorf("Expected a probe to reach DynamoDB and fail, but got '%v'", err)
+	}
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the circuit to open again after a failed probe, but got '%v'", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err != nil {
+		t.Errorf("Expected the probe to succeed, but got '%s'", err.Error())
+	}
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err != nil {
+		t.Errorf("Expected the circuit to be closed, but got '%s'", err.Error())
+	}
+}
+
+func TestCircuitBreakerCanceledProbe(t *testing.T) {
+	r := setup(t, Config{MaxAttempts: 1, FailureThreshold: 1, Cooldown: 30 * time.Second})
+
+	failGetItem(dynamodb.ErrCodeProvisionedThroughputExceededException, 1)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil {
+		t.Fatalf("Expected the throttled call to fail")
+	}
+
+	// The probe is cancelled, which says nothing about DynamoDB, so the next call gets to probe instead
+	now = now.Add(time.Minute)
+	calls := failGetItem(request.CanceledErrorCode, 1)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the probe to be cancelled, but got %v", err)
+	}
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err != nil {
+		t.Errorf("Expected a new probe to be let through, but got '%s'", err.Error())
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 calls, but there were %d", *calls)
+	}
+
+	// Same when the probe's retry is cut short by the context
+	r.maxAttempts = 3
+	r.sleep = func(context.Context, time.Duration) error { return context.Canceled }
+	r.breaker.failure()
+	now = now.Add(time.Minute)
+	calls = failGetItem(dynamodb.ErrCodeProvisionedThroughputExceededException, 1)
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err == nil {
+		t.Errorf("Expected the probe to fail when its retry was cancelled")
+	}
+	if _, err := r.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{}); err != nil {
+		t.Errorf("Expected a new probe to be let through, but got '%s'", err.Error())
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 calls, but there were %d", *calls)
+	}
+}
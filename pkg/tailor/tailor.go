@@ -0,0 +1,191 @@
+package tailor
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/render"
+)
+
+const (
+	// DefaultPageBudget is how many PDF pages the tailored resume is trimmed to fit when no budget is given
+	DefaultPageBudget = 1
+
+	ErrorEmptyJobDescription = "job description has no usable keywords"
+
+	// maxKeywords limits how many matched and missing keywords are reported
+	maxKeywords = 10
+)
+
+var ErrEmptyJobDescription = errors.New(ErrorEmptyJobDescription)
+
+// Result is the tailored resume and how well the profile matches the posting. Score is the share of the posting's
+// keyword weight found anywhere in the profile, from 0 to 1.
+type Result struct {
+	User    *models.User `json:"-" xml:"-"`
+	Score   float64      `json:"score" xml:"score"`
+	Matched []string     `json:"matched_keywords" xml:"matched_keywords>keyword"`
+	Missing []string     `json:"missing_keywords" xml:"missing_keywords>keyword"`
+	Trimmed int          `json:"trimmed" xml:"trimmed"`
+	Pages   int          `json:"pages" xml:"pages"`
+	Budget  int          `json:"page_budget" xml:"page_budget"`
+}
+
+// item is a responsibility or skill that can be reordered or trimmed
+type item struct {
+	experience int // index into Experience, or -1 for a skill
+	index      int // index into the Responsibilities or Skills it came from
+	score      float64
+}
+
+// Tailor ranks each position's responsibilities and the skills by their TF-IDF similarity to the job description,
+// then trims the least relevant of them until the resume fits within pageBudget pages. Positions themselves are kept
+// in their original order so the work history stays complete.
+func Tailor(user *models.User, jobDescription string, pageBudget int) (*Result, error) {
+	posting := Tokenize(jobDescription)
+	if len(posting) == 0 {
+		return nil, ErrEmptyJobDescription
+	}
+
+	if pageBudget < 1 {
+		pageBudget = DefaultPageBudget
+	}
+
+	documents := [][]string{posting}
+	var items []item
+	var itemTerms [][]string
+	for i, exp := range user.Experience {
+		for j, responsibility := range exp.Responsibilities {
+			items = append(items, item{experience: i, index: j})
+			itemTerms = append(itemTerms, Tokenize(responsibility))
+		}
+	}
+	for j, skill := range user.Skills {
+		items = append(items, item{experience: -1, index: j})
+		itemTerms = append(itemTerms, Tokenize(skill.Name))
+	}
+	documents = append(documents, itemTerms...)
+
+	c := newCorpus(documents)
+	postingVector := c.vector(posting)
+	for i := range items {
+		items[i].score = cosine(c.vector(itemTerms[i]), postingVector)
+	}
+
+	// Most relevant first, keeping the profile's order between equally relevant items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].score > items[j].score
+	})
+
+	result := &Result{Budget: pageBudget}
+	result.Score, result.Matched, result.Missing = match(user, postingVector, jobDescription)
+
+	// Every count lays out the whole resume, so binary search for the most items that fit rather than trimming one at a
+	// time. Keeping more items never takes fewer pages.
+	pages := make(map[int]int)
+	pagesKeeping := func(kept int) int {
+		if _, ok := pages[kept]; !ok {
+			pages[kept] = render.PageCount(render.FromUser(build(user, items[:kept])))
+		}
+		return pages[kept]
+	}
+	kept := sort.Search(len(items)+1, func(kept int) bool {
+		return pagesKeeping(kept) > pageBudget
+	}) - 1
+	if kept < 0 {
+		kept = 0
+	}
+
+	result.User = build(user, items[:kept])
+	result.Trimmed = len(items) - kept
+	result.Pages = pagesKeeping(kept)
+
+	return result, nil
+}
+
+// build copies the user with only the kept responsibilities and skills, each in order of relevance
+func build(user *models.User, kept []item) *models.User {
+	tailored := *user
+	tailored.Experience = make([]models.Experience, len(user.Experience))
+	for i, exp := range user.Experience {
+		tailored.Experience[i] = exp
+		tailored.Experience[i].Responsibilities = nil
+	}
+	tailored.Skills = nil
+
+	for _, it := range kept {
+		if it.experience < 0 {
+			tailored.Skills = append(tailored.Skills, user.Skills[it.index])
+		} else {
+			exp := &tailored.Experience[it.experience]
+			exp.Responsibilities = append(exp.Responsibilities, user.Experience[it.experience].Responsibilities[it.index])
+		}
+	}
+
+	return &tailored
+}
+
+// match weighs the posting's keywords found anywhere in the profile against those that aren't. Keywords are reported
+// the way the posting first wrote them rather than as folded terms.
+func match(user *models.User, posting vector, jobDescription string) (float64, []string, []string) {
+	written := make(map[string]string)
+	for _, word := range words(jobDescription) {
+		if _, ok := written[stem(word)]; !ok {
+			written[stem(word)] = word
+		}
+	}
+
+	profile := make(map[string]bool)
+	for _, term := range Tokenize(profileText(user)) {
+		profile[term] = true
+	}
+
+	terms := make([]string, 0, len(posting))
+	for term := range posting {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if posting[terms[i]] == posting[terms[j]] {
+			return terms[i] < terms[j]
+		}
+		return posting[terms[i]] > posting[terms[j]]
+	})
+
+	var total, found float64
+	matched := []string{}
+	missing := []string{}
+	for _, term := range terms {
+		total += posting[term]
+		if profile[term] {
+			found += posting[term]
+			if len(matched) < maxKeywords {
+				matched = append(matched, written[term])
+			}
+		} else if len(missing) < maxKeywords {
+			missing = append(missing, written[term])
+		}
+	}
+
+	return found / total, matched, missing
+}
+
+func profileText(user *models.User) string {
+	parts := []string{user.Summary}
+	for _, exp := range user.Experience {
+		parts = append(parts, exp.JobTitle, exp.Company)
+		parts = append(parts, exp.Responsibilities...)
+	}
+	for _, skill := range user.Skills {
+		parts = append(parts, skill.Name)
+	}
+	for _, degree := range user.Degrees {
+		parts = append(parts, degree.Degree, degree.Major)
+	}
+	for _, cert := range user.Certifications {
+		parts = append(parts, cert.Name)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,137 @@
+package tailor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/bkimbrough88/resume-backend/pkg/models"
+	"github.com/bkimbrough88/resume-backend/pkg/render"
+)
+
+const posting = "We are hiring a backend engineer to build Go services on AWS Lambda and DynamoDB. Kubernetes is a plus."
+
+func testUser() *models.User {
+	return &models.User{
+		UserId:    "user1",
+		GivenName: "Test",
+		SurName:   "User",
+		Experience: []models.Experience{
+			{
+				Company:  "Co",
+				JobTitle: "Engineer",
+				Responsibilities: []string{
+					"Organised the office holiday party",
+					"Built Go services running on AWS Lambda",
+					"Designed DynamoDB tables for the billing service",
+				},
+			},
+			{
+				Company:          "Other Co",
+				JobTitle:         "Intern",
+				Responsibilities: []string{"Answered support tickets", "Deployed workloads to Kubernetes"},
+			},
+		},
+		Skills: []models.Skill{{Name: "Photoshop"}, {Name: "Go"}, {Name: "DynamoDB"}},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	expected := []string{"c++", "c#", "service", "service", "class"}
+	if terms := Tokenize("C++ and C# services, with the service class."); !reflect.DeepEqual(expected, terms) {
+		t.Errorf("Expected terms to be %v, but was %v", expected, terms)
+	}
+}
+
+func TestTailorRanks(t *testing.T) {
+	user := testUser()
+	result, err := Tailor(user, posting, 5)
+	if err != nil {
+		t.Fatalf("Failed to tailor: %s", err.Error())
+	}
+
+	if 0 != result.Trimmed {
+		t.Errorf("Expected nothing to be trimmed, but %d items were", result.Trimmed)
+	}
+
+	if first := result.User.Experience[0].Responsibilities; "Organised the office holiday party" != first[len(first)-1] {
+		t.Errorf("Expected the irrelevant responsibility to be ranked last, but was %v", first)
+	}
+
+	if "Co" != result.User.Experience[0].Company || "Other Co" != result.User.Experience[1].Company {
+		t.Errorf("Expected positions to keep their order, but were %+v", result.User.Experience)
+	}
+
+	if "Deployed workloads to Kubernetes" != result.User.Experience[1].Responsibilities[0] {
+		t.Errorf("Expected the Kubernetes responsibility to be ranked first, but was %v", result.User.Experience[1].Responsibilities)
+	}
+
+	if "Photoshop" != result.User.Skills[len(result.User.Skills)-1].Name {
+		t.Errorf("Expected the irrelevant skill to be ranked last, but was %+v", result.User.Skills)
+	}
+
+	if 3 != len(user.Experience[0].Responsibilities) || "Organised the office holiday party" != user.Experience[0].Responsibilities[0] {
+		t.Errorf("Expected the original user to be left unchanged, but was %v", user.Experience[0].Responsibilities)
+	}
+}
+
+func TestTailorTrimsToBudget(t *testing.T) {
+	user := testUser()
+	for i := 0; i < 80; i++ {
+		user.Experience[0].Responsibilities = append(user.Experience[0].Responsibilities, fmt.Sprintf("Filed weekly report number %d", i))
+	}
+
+	if pages := render.PageCount(render.FromUser(user)); pages < 2 {
+		t.Fatalf("Expected the untailored resume to run over a page, but was %d pages", pages)
+	}
+
+	result, err := Tailor(user, posting, 1)
+	if err != nil {
+		t.Fatalf("Failed to tailor: %s", err.Error())
+	}
+
+	if 1 != result.Pages || 1 != render.PageCount(render.FromUser(result.User)) {
+		t.Errorf("Expected the tailored resume to fit on 1 page, but was %d", result.Pages)
+	}
+
+	if result.Trimmed == 0 {
+		t.Errorf("Expected items to be trimmed")
+	}
+
+	kept := result.User.Experience[0].Responsibilities
+	if "Built Go services running on AWS Lambda" != kept[0] {
+		t.Errorf("Expected the most relevant responsibility to be kept first, but was %v", kept)
+	}
+}
+
+func TestTailorScore(t *testing.T) {
+	result, err := Tailor(testUser(), posting, 0)
+	if err != nil {
+		t.Fatalf("Failed to tailor: %s", err.Error())
+	}
+
+	if DefaultPageBudget != result.Budget {
+		t.Errorf("Expected page budget to default to %d, but was %d", DefaultPageBudget, result.Budget)
+	}
+
+	if result.Score <= 0 || result.Score >= 1 {
+		t.Errorf("Expected a partial match score, but was %f", result.Score)
+	}
+
+	if !contains(result.Matched, "kubernetes") || !contains(result.Missing, "hiring") {
+		t.Errorf("Expected kubernetes to match and hiring to be missing, but matched %v and missed %v", result.Matched, result.Missing)
+	}
+
+	if _, err := Tailor(testUser(), "the and of", 1); err == nil || ErrorEmptyJobDescription != err.Error() {
+		t.Errorf("Expected error '%s', but was %v", ErrorEmptyJobDescription, err)
+	}
+}
+
+func contains(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
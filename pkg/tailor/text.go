@@ -0,0 +1,114 @@
+package tailor
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// stopWords are too common in job postings to say anything about fit
+var stopWords = map[string]bool{
+	"a": true, "about": true, "all": true, "an": true, "and": true, "any": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "for": true, "from": true, "have": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "job": true, "more": true, "of": true, "on": true, "or": true, "our": true, "role": true,
+	"team": true, "that": true, "the": true, "their": true, "this": true, "to": true, "we": true, "what": true,
+	"who": true, "will": true, "with": true, "work": true, "you": true, "your": true, "year": true, "experience": true,
+}
+
+// Tokenize lowercases the text and splits it into terms, keeping the + and # that are part of names like C++ and C#.
+// Plurals are folded into their singular so "services" matches "service".
+func Tokenize(text string) []string {
+	terms := words(text)
+	for i, word := range terms {
+		terms[i] = stem(word)
+	}
+
+	return terms
+}
+
+// words splits the text the way Tokenize does, without folding plurals
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	var words []string
+	for _, field := range fields {
+		field = strings.TrimLeft(field, "+#")
+		if len(field) < 2 || stopWords[field] {
+			continue
+		}
+
+		words = append(words, field)
+	}
+
+	return words
+}
+
+func stem(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return strings.TrimSuffix(word, "s")
+	}
+
+	return word
+}
+
+// vector is a document's TF-IDF weights by term
+type vector map[string]float64
+
+// corpus holds the document frequency of every term, so weights favour the terms that set documents apart
+type corpus struct {
+	documents int
+	frequency map[string]int
+}
+
+func newCorpus(documents [][]string) *corpus {
+	c := &corpus{documents: len(documents), frequency: make(map[string]int)}
+	for _, terms := range documents {
+		seen := make(map[string]bool)
+		for _, term := range terms {
+			if !seen[term] {
+				seen[term] = true
+				c.frequency[term]++
+			}
+		}
+	}
+
+	return c
+}
+
+// idf is smoothed so terms found in every document still count for something
+func (c *corpus) idf(term string) float64 {
+	return math.Log(float64(c.documents+1)/float64(c.frequency[term]+1)) + 1
+}
+
+func (c *corpus) vector(terms []string) vector {
+	v := make(vector)
+	for _, term := range terms {
+		v[term]++
+	}
+
+	for term, count := range v {
+		v[term] = count / float64(len(terms)) * c.idf(term)
+	}
+
+	return v
+}
+
+func cosine(a vector, b vector) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		dot += weight * b[term]
+		normA += weight * weight
+	}
+
+	for _, weight := range b {
+		normB += weight * weight
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}